@@ -0,0 +1,167 @@
+package hw04lrucache
+
+import (
+	"fmt"
+	"math"
+)
+
+// nilIndex marks the absence of a neighbour node in the arena.
+const nilIndex int32 = -1
+
+// arenaNode is a queue element stored by value in the arena and linked by indices instead of pointers.
+type arenaNode struct {
+	key   Key
+	value interface{}
+	prev  int32
+	next  int32
+}
+
+// arenaCache is an LRU cache backed by a preallocated slice of nodes.
+// Unlike lruCache it does not allocate per entry and keeps no *ListItem pointers,
+// so the garbage collector has far less to scan in large caches.
+type arenaCache struct {
+	capacity int
+	nodes    []arenaNode
+	items    map[Key]int32
+	head     int32 // most recently used node
+	tail     int32 // least recently used node
+	free     int32 // head of the free list, linked by next
+	used     int32 // number of arena slots handed out at least once
+}
+
+func (c *arenaCache) Set(key Key, value interface{}) bool {
+	if idx, ok := c.items[key]; ok {
+		c.nodes[idx].value = value
+		c.moveToFront(idx)
+		return true
+	}
+
+	if c.capacity <= 0 {
+		return false
+	}
+
+	if len(c.items) == c.capacity {
+		c.evict()
+	}
+
+	idx := c.alloc()
+	c.nodes[idx] = arenaNode{key: key, value: value, prev: nilIndex, next: nilIndex}
+	c.pushFront(idx)
+	c.items[key] = idx
+	return false
+}
+
+func (c *arenaCache) Get(key Key) (interface{}, bool) {
+	if idx, ok := c.items[key]; ok {
+		c.moveToFront(idx)
+		return c.nodes[idx].value, true
+	}
+	return nil, false
+}
+
+func (c *arenaCache) Remove(key Key) bool {
+	idx, ok := c.items[key]
+	if !ok {
+		return false
+	}
+
+	delete(c.items, key)
+	c.unlink(idx)
+	c.release(idx)
+	return true
+}
+
+func (c *arenaCache) Clear() {
+	// Drop references to keys and values so they can be collected, the arena itself is reused.
+	for i := range c.nodes[:c.used] {
+		c.nodes[i] = arenaNode{}
+	}
+	c.items = make(map[Key]int32, c.capacity)
+	c.head, c.tail, c.free = nilIndex, nilIndex, nilIndex
+	c.used = 0
+}
+
+func (c *arenaCache) alloc() int32 {
+	if c.free != nilIndex {
+		idx := c.free
+		c.free = c.nodes[idx].next
+		return idx
+	}
+
+	idx := c.used
+	c.used++
+	return idx
+}
+
+func (c *arenaCache) release(idx int32) {
+	c.nodes[idx] = arenaNode{next: c.free, prev: nilIndex}
+	c.free = idx
+}
+
+func (c *arenaCache) evict() {
+	idx := c.tail
+	delete(c.items, c.nodes[idx].key)
+	c.unlink(idx)
+	c.release(idx)
+}
+
+func (c *arenaCache) pushFront(idx int32) {
+	node := &c.nodes[idx]
+	node.prev = nilIndex
+	node.next = c.head
+
+	if c.head != nilIndex {
+		c.nodes[c.head].prev = idx
+	}
+	c.head = idx
+
+	if c.tail == nilIndex {
+		c.tail = idx
+	}
+}
+
+func (c *arenaCache) unlink(idx int32) {
+	node := &c.nodes[idx]
+
+	if node.prev == nilIndex {
+		c.head = node.next
+	} else {
+		c.nodes[node.prev].next = node.next
+	}
+
+	if node.next == nilIndex {
+		c.tail = node.prev
+	} else {
+		c.nodes[node.next].prev = node.prev
+	}
+
+	node.prev, node.next = nilIndex, nilIndex
+}
+
+func (c *arenaCache) moveToFront(idx int32) {
+	if c.head == idx {
+		return
+	}
+	c.unlink(idx)
+	c.pushFront(idx)
+}
+
+// NewArenaCache creates an LRU cache whose queue lives in a single preallocated slice.
+// It panics if capacity doesn't fit into int32, the type of node indices.
+func NewArenaCache(capacity int) Cache {
+	if capacity < 0 {
+		capacity = 0
+	}
+	if int64(capacity) > math.MaxInt32 {
+		panic(fmt.Sprintf("hw04lrucache: arena capacity %d doesn't fit into int32", capacity))
+	}
+
+	return &arenaCache{
+		capacity: capacity,
+		nodes:    make([]arenaNode, capacity),
+		items:    make(map[Key]int32, capacity),
+		head:     nilIndex,
+		tail:     nilIndex,
+		free:     nilIndex,
+	}
+}
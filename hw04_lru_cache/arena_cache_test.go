@@ -0,0 +1,208 @@
+package hw04lrucache
+
+import (
+	"math"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArenaCache(t *testing.T) {
+	t.Run("empty cache", func(t *testing.T) {
+		c := NewArenaCache(10)
+
+		_, ok := c.Get("aaa")
+		require.False(t, ok)
+	})
+
+	t.Run("simple", func(t *testing.T) {
+		c := NewArenaCache(5)
+
+		require.False(t, c.Set("aaa", 100))
+		require.False(t, c.Set("bbb", 200))
+
+		val, ok := c.Get("aaa")
+		require.True(t, ok)
+		require.Equal(t, 100, val)
+
+		require.True(t, c.Set("aaa", 300))
+
+		val, ok = c.Get("aaa")
+		require.True(t, ok)
+		require.Equal(t, 300, val)
+
+		val, ok = c.Get("ccc")
+		require.False(t, ok)
+		require.Nil(t, val)
+	})
+
+	t.Run("removing long-used", func(t *testing.T) {
+		c := NewArenaCache(3)
+
+		c.Set("aaa", 100)
+		c.Set("bbb", 200)
+		c.Set("ccc", 300)
+		c.Get("aaa")
+		c.Set("bbb", 400)
+		c.Set("fff", 500) // ccc is the least recently used
+
+		_, ok := c.Get("ccc")
+		require.False(t, ok)
+
+		for key, expected := range map[Key]int{"aaa": 100, "bbb": 400, "fff": 500} {
+			val, ok := c.Get(key)
+			require.True(t, ok)
+			require.Equal(t, expected, val)
+		}
+	})
+
+	t.Run("clear and reuse arena", func(t *testing.T) {
+		c := NewArenaCache(2)
+
+		c.Set("aaa", 100)
+		c.Set("bbb", 200)
+		c.Clear()
+
+		_, ok := c.Get("aaa")
+		require.False(t, ok)
+
+		for i := 0; i < 10; i++ {
+			c.Set(Key(strconv.Itoa(i)), i)
+		}
+
+		_, ok = c.Get("7")
+		require.False(t, ok)
+
+		val, ok := c.Get("8")
+		require.True(t, ok)
+		require.Equal(t, 8, val)
+
+		val, ok = c.Get("9")
+		require.True(t, ok)
+		require.Equal(t, 9, val)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		c := NewArenaCache(2)
+
+		c.Set("aaa", 100)
+		c.Set("bbb", 200)
+
+		require.True(t, c.Remove("aaa"))
+		require.False(t, c.Remove("aaa"))
+
+		_, ok := c.Get("aaa")
+		require.False(t, ok)
+
+		c.Set("ccc", 300)
+		c.Set("ddd", 400)
+
+		_, ok = c.Get("bbb")
+		require.False(t, ok)
+
+		val, ok := c.Get("ccc")
+		require.True(t, ok)
+		require.Equal(t, 300, val)
+	})
+
+	t.Run("zero capacity", func(t *testing.T) {
+		c := NewArenaCache(0)
+
+		require.False(t, c.Set("aaa", 100))
+
+		_, ok := c.Get("aaa")
+		require.False(t, ok)
+	})
+
+	t.Run("capacity above int32", func(t *testing.T) {
+		require.Panics(t, func() { NewArenaCache(math.MaxInt32 + 1) })
+	})
+
+	t.Run("same behaviour as list cache", func(t *testing.T) {
+		arena := NewArenaCache(16)
+		list := NewCache(16)
+
+		for i := 0; i < 10_000; i++ {
+			key := Key(strconv.Itoa((i * 7919) % 37))
+			switch i % 5 {
+			case 0, 3:
+				require.Equal(t, list.Set(key, i), arena.Set(key, i))
+				continue
+			case 4:
+				require.Equal(t, list.Remove(key), arena.Remove(key))
+				continue
+			}
+
+			listVal, listOk := list.Get(key)
+			arenaVal, arenaOk := arena.Get(key)
+			require.Equal(t, listOk, arenaOk)
+			require.Equal(t, listVal, arenaVal)
+		}
+	})
+}
+
+const benchCapacity = 1 << 20
+
+func benchmarkCacheSetGet(b *testing.B, newCache func(capacity int) Cache) {
+	b.Helper()
+
+	c := newCache(benchCapacity)
+	keys := make([]Key, 2*benchCapacity)
+	for i := range keys {
+		keys[i] = Key(strconv.Itoa(i))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		key := keys[i%len(keys)]
+		if _, ok := c.Get(key); !ok {
+			c.Set(key, i)
+		}
+	}
+}
+
+// benchmarkCacheGC measures how long a full collection takes while a filled cache is alive.
+func benchmarkCacheGC(b *testing.B, newCache func(capacity int) Cache) {
+	b.Helper()
+
+	c := newCache(benchCapacity)
+	for i := 0; i < benchCapacity; i++ {
+		c.Set(Key(strconv.Itoa(i)), i)
+	}
+
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	pauseBefore := stats.PauseTotalNs
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+	}
+	b.StopTimer()
+
+	runtime.ReadMemStats(&stats)
+	b.ReportMetric(float64(stats.PauseTotalNs-pauseBefore)/float64(b.N), "gc-pause-ns/op")
+
+	runtime.KeepAlive(c)
+}
+
+func BenchmarkListCacheSetGet(b *testing.B) {
+	benchmarkCacheSetGet(b, NewCache)
+}
+
+func BenchmarkArenaCacheSetGet(b *testing.B) {
+	benchmarkCacheSetGet(b, NewArenaCache)
+}
+
+func BenchmarkListCacheGC(b *testing.B) {
+	benchmarkCacheGC(b, NewCache)
+}
+
+func BenchmarkArenaCacheGC(b *testing.B) {
+	benchmarkCacheGC(b, NewArenaCache)
+}
@@ -5,6 +5,7 @@ type Key string
 type Cache interface {
 	Set(key Key, value interface{}) bool
 	Get(key Key) (interface{}, bool)
+	Remove(key Key) bool
 	Clear()
 }
 
@@ -41,6 +42,17 @@ func (c *lruCache) Get(key Key) (interface{}, bool) {
 	return nil, false
 }
 
+func (c *lruCache) Remove(key Key) bool {
+	listItem, ok := c.items[key]
+	if !ok {
+		return false
+	}
+
+	delete(c.items, key)
+	c.queue.Remove(listItem)
+	return true
+}
+
 func (c *lruCache) Clear() {
 	c.queue = NewList()
 	c.items = make(map[Key]*ListItem, c.capacity)
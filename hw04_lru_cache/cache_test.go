@@ -114,6 +114,29 @@ func TestCache(t *testing.T) {
 		require.Equal(t, 400, val)
 	})
 
+	t.Run("remove", func(t *testing.T) {
+		c := NewCache(2)
+
+		c.Set("aaa", 100)
+		c.Set("bbb", 200)
+
+		require.True(t, c.Remove("aaa"))
+		require.False(t, c.Remove("aaa"))
+
+		_, ok := c.Get("aaa")
+		require.False(t, ok)
+
+		c.Set("ccc", 300)
+		c.Set("ddd", 400) // [ddd, ccc], bbb was the least recently used
+
+		_, ok = c.Get("bbb")
+		require.False(t, ok)
+
+		val, ok := c.Get("ccc")
+		require.True(t, ok)
+		require.Equal(t, 300, val)
+	})
+
 	t.Run("test nil", func(t *testing.T) {
 		c := NewCache(3)
 
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	hw04lrucache "github.com/a-klimenko/go-otus-hw/hw04_lru_cache"
+)
+
+var (
+	addr        string
+	capacity    int
+	maxItemSize int
+	arena       bool
+)
+
+func init() {
+	flag.StringVar(&addr, "addr", ":11211", "address to listen on")
+	flag.IntVar(&capacity, "capacity", 1024, "maximum number of items in the cache")
+	flag.IntVar(&maxItemSize, "max-item-size", 1<<20, "maximum size of a single value in bytes")
+	flag.BoolVar(&arena, "arena", false, "use the arena-backed cache implementation")
+}
+
+func main() {
+	flag.Parse()
+	if maxItemSize <= 0 || maxItemSize > maxDataSize {
+		log.Fatalf("Max item size must be from 1 to %d bytes", maxDataSize)
+	}
+
+	var cache hw04lrucache.Cache
+	if arena {
+		cache = hw04lrucache.NewArenaCache(capacity)
+	} else {
+		cache = hw04lrucache.NewCache(capacity)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf("Cannot listen on %s: %v", addr, err)
+	}
+	log.Printf("cache server is listening on %s", ln.Addr())
+
+	server := NewServer(cache, maxItemSize)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	go func() {
+		<-sigs
+		if err := server.Close(); err != nil {
+			log.Printf("Cannot close server: %v", err)
+		}
+	}()
+
+	if err := server.Serve(ln); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
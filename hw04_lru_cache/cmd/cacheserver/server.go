@@ -0,0 +1,580 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	hw04lrucache "github.com/a-klimenko/go-otus-hw/hw04_lru_cache"
+)
+
+const (
+	maxKeyLength  = 250
+	maxLineLength = 2048
+	// Data blocks above this are refused whatever the maximum item size is.
+	maxDataSize = 1 << 30
+	// Exptime values above this are treated by memcached as absolute unix timestamps.
+	maxRelativeExptime = 60 * 60 * 24 * 30
+)
+
+var (
+	errLineTooLong  = errors.New("line too long")
+	errBadDataChunk = errors.New("bad data chunk")
+	errDataTooLarge = errors.New("object too large for cache")
+	errQuit         = errors.New("quit")
+)
+
+// entry is a value stored in the cache on behalf of a memcached client.
+type entry struct {
+	data      []byte
+	flags     uint32
+	expiresAt time.Time
+	cas       uint64
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+type stats struct {
+	currConnections  int
+	totalConnections int
+	cmdGet           int
+	cmdSet           int
+	cmdTouch         int
+	cmdFlush         int
+	getHits          int
+	getMisses        int
+	deleteHits       int
+	deleteMisses     int
+	touchHits        int
+	touchMisses      int
+}
+
+// Server serves a subset of the memcached text protocol on top of hw04lrucache.Cache.
+type Server struct {
+	maxItemSize int
+	started     time.Time
+	now         func() time.Time
+
+	mu        sync.Mutex // guards cache, casUnique, flushAt and stats
+	cache     hw04lrucache.Cache
+	casUnique uint64
+	flushAt   time.Time
+	stats     stats
+
+	connsMu  sync.Mutex
+	conns    map[net.Conn]struct{}
+	listener net.Listener
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+func NewServer(cache hw04lrucache.Cache, maxItemSize int) *Server {
+	return &Server{
+		maxItemSize: maxItemSize,
+		started:     time.Now(),
+		now:         time.Now,
+		cache:       cache,
+		conns:       make(map[net.Conn]struct{}),
+	}
+}
+
+// Serve accepts connections until the listener fails or the server is closed.
+func (s *Server) Serve(ln net.Listener) error {
+	s.connsMu.Lock()
+	if s.closed {
+		s.connsMu.Unlock()
+		return ln.Close()
+	}
+	s.listener = ln
+	s.connsMu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.connsMu.Lock()
+			closed := s.closed
+			s.connsMu.Unlock()
+			if closed {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+
+		if !s.trackConn(conn) {
+			conn.Close()
+			return nil
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.untrackConn(conn)
+			s.handleConn(conn)
+		}()
+	}
+}
+
+// Close stops accepting connections, closes the active ones and waits for their handlers.
+func (s *Server) Close() error {
+	s.connsMu.Lock()
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.connsMu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) trackConn(conn net.Conn) bool {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+
+	s.mu.Lock()
+	s.stats.currConnections++
+	s.stats.totalConnections++
+	s.mu.Unlock()
+
+	return true
+}
+
+func (s *Server) untrackConn(conn net.Conn) {
+	s.connsMu.Lock()
+	delete(s.conns, conn)
+	s.connsMu.Unlock()
+
+	s.mu.Lock()
+	s.stats.currConnections--
+	s.mu.Unlock()
+
+	conn.Close()
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	r := bufio.NewReaderSize(conn, maxLineLength)
+	w := bufio.NewWriter(conn)
+
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			if errors.Is(err, errLineTooLong) {
+				writeClientError(w, err)
+				w.Flush()
+			}
+			return
+		}
+
+		err = s.dispatch(line, r, w)
+		if errors.Is(err, errQuit) {
+			return
+		}
+		if errors.Is(err, errBadDataChunk) {
+			// The rest of the stream can't be trusted after a malformed data block.
+			writeClientError(w, err)
+			w.Flush()
+			return
+		}
+		if errors.Is(err, errDataTooLarge) {
+			// The data block is too large to be swallowed.
+			writeServerError(w, err.Error())
+			w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", errLineTooLong
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// dispatch executes a single command. A returned error means the connection must be closed.
+func (s *Server) dispatch(line string, r *bufio.Reader, w *bufio.Writer) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		w.WriteString("ERROR\r\n")
+		return nil
+	}
+
+	args := fields[1:]
+	switch fields[0] {
+	case "get":
+		s.handleGet(args, false, w)
+	case "gets":
+		s.handleGet(args, true, w)
+	case "set", "add":
+		return s.handleStore(fields[0], args, r, w)
+	case "delete":
+		s.handleDelete(args, w)
+	case "touch":
+		s.handleTouch(args, w)
+	case "stats":
+		s.handleStats(args, w)
+	case "flush_all":
+		s.handleFlushAll(args, w)
+	case "version":
+		w.WriteString("VERSION hw04lrucache\r\n")
+	case "quit":
+		return errQuit
+	default:
+		w.WriteString("ERROR\r\n")
+	}
+
+	return nil
+}
+
+func (s *Server) handleGet(keys []string, withCAS bool, w *bufio.Writer) {
+	if len(keys) == 0 {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+	for _, key := range keys {
+		if err := validateKey(key); err != nil {
+			writeClientError(w, err)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	found := make([]entry, len(keys))
+	hits := make([]bool, len(keys))
+	for i, key := range keys {
+		found[i], hits[i] = s.lookup(hw04lrucache.Key(key))
+		s.stats.cmdGet++
+		if hits[i] {
+			s.stats.getHits++
+		} else {
+			s.stats.getMisses++
+		}
+	}
+	s.mu.Unlock()
+
+	for i, key := range keys {
+		if !hits[i] {
+			continue
+		}
+		e := found[i]
+		if withCAS {
+			fmt.Fprintf(w, "VALUE %s %d %d %d\r\n", key, e.flags, len(e.data), e.cas)
+		} else {
+			fmt.Fprintf(w, "VALUE %s %d %d\r\n", key, e.flags, len(e.data))
+		}
+		w.Write(e.data)
+		w.WriteString("\r\n")
+	}
+	w.WriteString("END\r\n")
+}
+
+// handleStore serves "set" and "add": <cmd> <key> <flags> <exptime> <bytes> [noreply].
+func (s *Server) handleStore(cmd string, args []string, r *bufio.Reader, w *bufio.Writer) error {
+	if len(args) != 4 && len(args) != 5 {
+		w.WriteString("ERROR\r\n")
+		return nil
+	}
+
+	key := args[0]
+	flags, flagsErr := strconv.ParseUint(args[1], 10, 32)
+	exptime, exptimeErr := strconv.ParseInt(args[2], 10, 64)
+	size, sizeErr := strconv.Atoi(args[3])
+	if flagsErr != nil || exptimeErr != nil || sizeErr != nil || size < 0 {
+		writeClientError(w, errors.New("bad command line format"))
+		return nil
+	}
+	if size > maxDataSize {
+		return errDataTooLarge
+	}
+	noreply := len(args) == 5 && args[4] == "noreply"
+
+	if s.maxItemSize > 0 && size > s.maxItemSize {
+		// Swallow the data block so the connection stays in sync.
+		if _, err := io.CopyN(io.Discard, r, int64(size)+2); err != nil {
+			return err
+		}
+		writeServerError(w, "object too large for cache")
+		return nil
+	}
+
+	// The block is buffered as it arrives, a client announcing a large size can't make the server
+	// allocate it up front.
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(size)+2); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	if data[size] != '\r' || data[size+1] != '\n' {
+		return errBadDataChunk
+	}
+	data = data[:size]
+
+	if err := validateKey(key); err != nil {
+		writeClientError(w, err)
+		return nil
+	}
+
+	s.mu.Lock()
+	now := s.now()
+	// A due flush must not wipe the value stored now.
+	s.flushIfDue(now)
+	s.stats.cmdSet++
+	stored := true
+	if cmd == "add" {
+		_, exists := s.lookup(hw04lrucache.Key(key))
+		stored = !exists
+	}
+	if stored {
+		s.casUnique++
+		s.cache.Set(hw04lrucache.Key(key), entry{
+			data:      data,
+			flags:     uint32(flags),
+			expiresAt: expiresAt(now, exptime),
+			cas:       s.casUnique,
+		})
+	}
+	s.mu.Unlock()
+
+	if noreply {
+		return nil
+	}
+	if stored {
+		w.WriteString("STORED\r\n")
+	} else {
+		w.WriteString("NOT_STORED\r\n")
+	}
+	return nil
+}
+
+// handleDelete serves "delete <key> [noreply]".
+func (s *Server) handleDelete(args []string, w *bufio.Writer) {
+	if len(args) != 1 && len(args) != 2 {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+	if err := validateKey(args[0]); err != nil {
+		writeClientError(w, err)
+		return
+	}
+	noreply := len(args) == 2 && args[1] == "noreply"
+
+	s.mu.Lock()
+	key := hw04lrucache.Key(args[0])
+	_, deleted := s.lookup(key)
+	if deleted {
+		s.cache.Remove(key)
+		s.stats.deleteHits++
+	} else {
+		s.stats.deleteMisses++
+	}
+	s.mu.Unlock()
+
+	if noreply {
+		return
+	}
+	if deleted {
+		w.WriteString("DELETED\r\n")
+	} else {
+		w.WriteString("NOT_FOUND\r\n")
+	}
+}
+
+// handleTouch serves "touch <key> <exptime> [noreply]".
+func (s *Server) handleTouch(args []string, w *bufio.Writer) {
+	if len(args) != 2 && len(args) != 3 {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+	if err := validateKey(args[0]); err != nil {
+		writeClientError(w, err)
+		return
+	}
+	exptime, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		writeClientError(w, errors.New("invalid exptime argument"))
+		return
+	}
+	noreply := len(args) == 3 && args[2] == "noreply"
+
+	s.mu.Lock()
+	key := hw04lrucache.Key(args[0])
+	e, touched := s.lookup(key)
+	s.stats.cmdTouch++
+	if touched {
+		e.expiresAt = expiresAt(s.now(), exptime)
+		s.cache.Set(key, e)
+		s.stats.touchHits++
+	} else {
+		s.stats.touchMisses++
+	}
+	s.mu.Unlock()
+
+	if noreply {
+		return
+	}
+	if touched {
+		w.WriteString("TOUCHED\r\n")
+	} else {
+		w.WriteString("NOT_FOUND\r\n")
+	}
+}
+
+func (s *Server) handleStats(args []string, w *bufio.Writer) {
+	if len(args) != 0 {
+		// Stats groups like "stats items" are not supported.
+		w.WriteString("END\r\n")
+		return
+	}
+
+	s.mu.Lock()
+	now := s.now()
+	st := s.stats
+	s.mu.Unlock()
+
+	writeStat := func(name string, value interface{}) {
+		fmt.Fprintf(w, "STAT %s %v\r\n", name, value)
+	}
+	writeStat("pid", os.Getpid())
+	writeStat("uptime", int64(now.Sub(s.started).Seconds()))
+	writeStat("time", now.Unix())
+	writeStat("curr_connections", st.currConnections)
+	writeStat("total_connections", st.totalConnections)
+	writeStat("cmd_get", st.cmdGet)
+	writeStat("cmd_set", st.cmdSet)
+	writeStat("cmd_touch", st.cmdTouch)
+	writeStat("cmd_flush", st.cmdFlush)
+	writeStat("get_hits", st.getHits)
+	writeStat("get_misses", st.getMisses)
+	writeStat("delete_hits", st.deleteHits)
+	writeStat("delete_misses", st.deleteMisses)
+	writeStat("touch_hits", st.touchHits)
+	writeStat("touch_misses", st.touchMisses)
+	w.WriteString("END\r\n")
+}
+
+// handleFlushAll serves "flush_all [delay] [noreply]".
+func (s *Server) handleFlushAll(args []string, w *bufio.Writer) {
+	noreply := len(args) > 0 && args[len(args)-1] == "noreply"
+	if noreply {
+		args = args[:len(args)-1]
+	}
+	if len(args) > 1 {
+		w.WriteString("ERROR\r\n")
+		return
+	}
+
+	var delay int64
+	if len(args) == 1 {
+		var err error
+		if delay, err = strconv.ParseInt(args[0], 10, 64); err != nil || delay < 0 {
+			writeClientError(w, errors.New("invalid delay argument"))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	s.stats.cmdFlush++
+	if delay == 0 {
+		s.cache.Clear()
+		s.flushAt = time.Time{}
+	} else {
+		s.flushAt = s.now().Add(time.Duration(delay) * time.Second)
+	}
+	s.mu.Unlock()
+
+	if !noreply {
+		w.WriteString("OK\r\n")
+	}
+}
+
+// lookup returns a live entry, dropping it if it has expired. Must be called with s.mu held.
+func (s *Server) lookup(key hw04lrucache.Key) (entry, bool) {
+	now := s.now()
+	s.flushIfDue(now)
+
+	v, ok := s.cache.Get(key)
+	if !ok {
+		return entry{}, false
+	}
+
+	e := v.(entry)
+	if e.expired(now) {
+		s.cache.Remove(key)
+		return entry{}, false
+	}
+	return e, true
+}
+
+// flushIfDue clears the cache if a delayed flush_all is due. Must be called with s.mu held.
+func (s *Server) flushIfDue(now time.Time) {
+	if !s.flushAt.IsZero() && !now.Before(s.flushAt) {
+		s.cache.Clear()
+		s.flushAt = time.Time{}
+	}
+}
+
+func expiresAt(now time.Time, exptime int64) time.Time {
+	switch {
+	case exptime == 0:
+		return time.Time{}
+	case exptime < 0:
+		return now
+	case exptime > maxRelativeExptime:
+		return time.Unix(exptime, 0)
+	default:
+		return now.Add(time.Duration(exptime) * time.Second)
+	}
+}
+
+func validateKey(key string) error {
+	if len(key) == 0 || len(key) > maxKeyLength {
+		return errors.New("invalid key length")
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return errors.New("invalid key characters")
+		}
+	}
+	return nil
+}
+
+func writeClientError(w *bufio.Writer, err error) {
+	fmt.Fprintf(w, "CLIENT_ERROR %s\r\n", err)
+}
+
+func writeServerError(w *bufio.Writer, msg string) {
+	fmt.Fprintf(w, "SERVER_ERROR %s\r\n", msg)
+}
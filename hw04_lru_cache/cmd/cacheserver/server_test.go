@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	hw04lrucache "github.com/a-klimenko/go-otus-hw/hw04_lru_cache"
+	"github.com/stretchr/testify/require"
+)
+
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func startServer(t *testing.T, capacity int) (*Server, string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewServer(hw04lrucache.NewCache(capacity), 1024)
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(ln)
+	}()
+
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+		require.NoError(t, <-done)
+	})
+
+	return server, ln.Addr().String()
+}
+
+func dial(t *testing.T, addr string) *testClient {
+	t.Helper()
+
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return &testClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *testClient) send(format string, args ...interface{}) {
+	c.t.Helper()
+
+	require.NoError(c.t, c.conn.SetWriteDeadline(time.Now().Add(time.Second)))
+	_, err := fmt.Fprintf(c.conn, format, args...)
+	require.NoError(c.t, err)
+}
+
+func (c *testClient) readLine() string {
+	c.t.Helper()
+
+	require.NoError(c.t, c.conn.SetReadDeadline(time.Now().Add(time.Second)))
+	line, err := c.r.ReadString('\n')
+	require.NoError(c.t, err)
+	require.True(c.t, strings.HasSuffix(line, "\r\n"), "line %q is not terminated by CRLF", line)
+	return strings.TrimSuffix(line, "\r\n")
+}
+
+func (c *testClient) expect(lines ...string) {
+	c.t.Helper()
+
+	for _, line := range lines {
+		require.Equal(c.t, line, c.readLine())
+	}
+}
+
+func TestServer(t *testing.T) {
+	t.Run("set and get", func(t *testing.T) {
+		_, addr := startServer(t, 10)
+		c := dial(t, addr)
+
+		c.send("set aaa 5 0 3\r\nfoo\r\n")
+		c.expect("STORED")
+
+		c.send("get aaa bbb\r\n")
+		c.expect("VALUE aaa 5 3", "foo", "END")
+
+		c.send("set aaa 0 0 0\r\n\r\n")
+		c.expect("STORED")
+
+		c.send("get aaa\r\n")
+		c.expect("VALUE aaa 0 0", "", "END")
+	})
+
+	t.Run("gets returns cas unique", func(t *testing.T) {
+		_, addr := startServer(t, 10)
+		c := dial(t, addr)
+
+		c.send("set aaa 0 0 1\r\na\r\nset bbb 0 0 1\r\nb\r\n")
+		c.expect("STORED", "STORED")
+
+		c.send("gets aaa bbb\r\n")
+		c.expect("VALUE aaa 0 1 1", "a", "VALUE bbb 0 1 2", "b", "END")
+	})
+
+	t.Run("add", func(t *testing.T) {
+		_, addr := startServer(t, 10)
+		c := dial(t, addr)
+
+		c.send("add aaa 0 0 3\r\nfoo\r\n")
+		c.expect("STORED")
+
+		c.send("add aaa 0 0 3\r\nbar\r\n")
+		c.expect("NOT_STORED")
+
+		c.send("get aaa\r\n")
+		c.expect("VALUE aaa 0 3", "foo", "END")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		_, addr := startServer(t, 10)
+		c := dial(t, addr)
+
+		c.send("set aaa 0 0 3\r\nfoo\r\n")
+		c.expect("STORED")
+
+		c.send("delete aaa\r\n")
+		c.expect("DELETED")
+
+		c.send("delete aaa\r\n")
+		c.expect("NOT_FOUND")
+
+		c.send("get aaa\r\n")
+		c.expect("END")
+	})
+
+	t.Run("expiration and touch", func(t *testing.T) {
+		server, addr := startServer(t, 10)
+		c := dial(t, addr)
+
+		var mu sync.Mutex
+		now := time.Unix(1_000_000, 0)
+		server.mu.Lock()
+		server.now = func() time.Time {
+			mu.Lock()
+			defer mu.Unlock()
+			return now
+		}
+		server.mu.Unlock()
+		advance := func(d time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			now = now.Add(d)
+		}
+
+		c.send("set aaa 0 10 3\r\nfoo\r\nset bbb 0 10 3\r\nbar\r\n")
+		c.expect("STORED", "STORED")
+
+		advance(5 * time.Second)
+		c.send("touch aaa 100\r\n")
+		c.expect("TOUCHED")
+
+		advance(5 * time.Second)
+		c.send("get aaa bbb\r\n")
+		c.expect("VALUE aaa 0 3", "foo", "END")
+
+		c.send("touch bbb 100\r\n")
+		c.expect("NOT_FOUND")
+
+		c.send("set ccc 0 -1 3\r\nbaz\r\n")
+		c.expect("STORED")
+		c.send("get ccc\r\n")
+		c.expect("END")
+	})
+
+	t.Run("flush_all", func(t *testing.T) {
+		_, addr := startServer(t, 10)
+		c := dial(t, addr)
+
+		c.send("set aaa 0 0 3\r\nfoo\r\n")
+		c.expect("STORED")
+
+		c.send("flush_all\r\n")
+		c.expect("OK")
+
+		c.send("get aaa\r\n")
+		c.expect("END")
+
+		c.send("flush_all noreply\r\nversion\r\n")
+		c.expect("VERSION hw04lrucache")
+	})
+
+	t.Run("delayed flush_all", func(t *testing.T) {
+		server, addr := startServer(t, 10)
+		c := dial(t, addr)
+
+		var mu sync.Mutex
+		now := time.Unix(1_000_000, 0)
+		server.mu.Lock()
+		server.now = func() time.Time {
+			mu.Lock()
+			defer mu.Unlock()
+			return now
+		}
+		server.mu.Unlock()
+		advance := func(d time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			now = now.Add(d)
+		}
+
+		c.send("set aaa 0 0 3\r\nfoo\r\nflush_all 10\r\n")
+		c.expect("STORED", "OK")
+
+		advance(5 * time.Second)
+		c.send("get aaa\r\n")
+		c.expect("VALUE aaa 0 3", "foo", "END")
+
+		// The flush is due, but the values stored after that are kept.
+		advance(5 * time.Second)
+		c.send("set bbb 0 0 3\r\nbar\r\nget aaa bbb\r\n")
+		c.expect("STORED", "VALUE bbb 0 3", "bar", "END")
+	})
+
+	t.Run("lru eviction", func(t *testing.T) {
+		_, addr := startServer(t, 2)
+		c := dial(t, addr)
+
+		c.send("set aaa 0 0 1\r\na\r\nset bbb 0 0 1\r\nb\r\n")
+		c.expect("STORED", "STORED")
+
+		c.send("get aaa\r\n")
+		c.expect("VALUE aaa 0 1", "a", "END")
+
+		c.send("set ccc 0 0 1\r\nc\r\n")
+		c.expect("STORED")
+
+		c.send("get aaa bbb ccc\r\n")
+		c.expect("VALUE aaa 0 1", "a", "VALUE ccc 0 1", "c", "END")
+	})
+
+	t.Run("noreply", func(t *testing.T) {
+		_, addr := startServer(t, 10)
+		c := dial(t, addr)
+
+		c.send("set aaa 0 0 3 noreply\r\nfoo\r\ndelete bbb noreply\r\nget aaa\r\n")
+		c.expect("VALUE aaa 0 3", "foo", "END")
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		_, addr := startServer(t, 10)
+		c := dial(t, addr)
+
+		c.send("set aaa 0 0 3\r\nfoo\r\nget aaa\r\nget bbb\r\n")
+		c.expect("STORED", "VALUE aaa 0 3", "foo", "END", "END")
+
+		c.send("stats\r\n")
+		stats := make(map[string]string)
+		for line := c.readLine(); line != "END"; line = c.readLine() {
+			parts := strings.Fields(line)
+			require.Len(t, parts, 3)
+			require.Equal(t, "STAT", parts[0])
+			stats[parts[1]] = parts[2]
+		}
+
+		require.Equal(t, "1", stats["curr_connections"])
+		require.Equal(t, "2", stats["cmd_get"])
+		require.Equal(t, "1", stats["cmd_set"])
+		require.Equal(t, "1", stats["get_hits"])
+		require.Equal(t, "1", stats["get_misses"])
+	})
+
+	t.Run("protocol errors", func(t *testing.T) {
+		_, addr := startServer(t, 10)
+		c := dial(t, addr)
+
+		c.send("unknown\r\n")
+		c.expect("ERROR")
+
+		c.send("set aaa x 0 3\r\n")
+		c.expect("CLIENT_ERROR bad command line format")
+
+		c.send("get %s\r\n", strings.Repeat("k", maxKeyLength+1))
+		c.expect("CLIENT_ERROR invalid key length")
+
+		c.send("set big 0 0 2048\r\n%s\r\n", strings.Repeat("x", 2048))
+		c.expect("SERVER_ERROR object too large for cache")
+
+		c.send("get big\r\n")
+		c.expect("END")
+
+		c.send("set aaa 0 0 3\r\nfoobar\r\n")
+		c.expect("CLIENT_ERROR bad data chunk")
+	})
+
+	t.Run("data size above the hard limit", func(t *testing.T) {
+		for _, size := range []string{strconv.Itoa(maxDataSize + 1), "9223372036854775807"} {
+			_, addr := startServer(t, 10)
+			c := dial(t, addr)
+
+			c.send("set big 0 0 %s\r\n", size)
+			c.expect("SERVER_ERROR object too large for cache")
+
+			_, err := c.r.ReadString('\n')
+			require.ErrorIs(t, err, io.EOF)
+		}
+	})
+
+	t.Run("quit closes connection", func(t *testing.T) {
+		_, addr := startServer(t, 10)
+		c := dial(t, addr)
+
+		c.send("quit\r\n")
+		require.NoError(t, c.conn.SetReadDeadline(time.Now().Add(time.Second)))
+		_, err := c.r.ReadString('\n')
+		require.Error(t, err)
+	})
+}
+
+func TestServerConcurrentClients(t *testing.T) {
+	_, addr := startServer(t, 1000)
+
+	const (
+		clients  = 50
+		requests = 100
+	)
+
+	wg := sync.WaitGroup{}
+	wg.Add(clients)
+	for i := 0; i < clients; i++ {
+		i := i
+		go func() {
+			defer wg.Done()
+
+			conn, err := net.DialTimeout("tcp", addr, time.Second)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+
+			for j := 0; j < requests; j++ {
+				key := fmt.Sprintf("key-%d-%d", i, j%10)
+				value := fmt.Sprintf("value-%d", j)
+
+				fmt.Fprintf(conn, "set %s 0 0 %d\r\n%s\r\nget %s\r\n", key, len(value), value, key)
+
+				expected := []string{
+					"STORED",
+					fmt.Sprintf("VALUE %s 0 %d", key, len(value)),
+					value,
+					"END",
+				}
+				for _, want := range expected {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					if got := strings.TrimSuffix(line, "\r\n"); got != want {
+						t.Errorf("expected %q, got %q", want, got)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,183 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	hw04lrucache "github.com/a-klimenko/go-otus-hw/hw04_lru_cache"
+)
+
+// BasePath is the HTTP path prefix under which nodes serve their local cache to peers.
+const BasePath = "/_cache/"
+
+var ErrUnexpectedStatus = errors.New("unexpected status from peer")
+
+// Node is a member of a distributed cache. Every key is owned by exactly one node on the ring,
+// other nodes forward reads and writes of that key to its owner over HTTP.
+type Node struct {
+	self     string
+	replicas int
+	client   *http.Client
+
+	ringMu sync.RWMutex
+	ring   *Ring
+
+	cacheMu sync.Mutex
+	cache   hw04lrucache.Cache
+}
+
+// NewNode creates a node reachable by peers at self, a base URL like "http://127.0.0.1:8080".
+func NewNode(self string, cache hw04lrucache.Cache, replicas int) *Node {
+	ring := NewRing(replicas, nil)
+	ring.Add(self)
+
+	return &Node{
+		self:     self,
+		replicas: replicas,
+		client:   http.DefaultClient,
+		ring:     ring,
+		cache:    cache,
+	}
+}
+
+// SetPeers replaces the ring membership. The node itself is always a member.
+func (n *Node) SetPeers(peers ...string) {
+	ring := NewRing(n.replicas, nil)
+	ring.Add(peers...)
+	ring.Add(n.self)
+
+	n.ringMu.Lock()
+	n.ring = ring
+	n.ringMu.Unlock()
+}
+
+// Owner returns the base URL of the node owning key.
+func (n *Node) Owner(key string) string {
+	n.ringMu.RLock()
+	defer n.ringMu.RUnlock()
+	return n.ring.Get(key)
+}
+
+func (n *Node) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	owner := n.Owner(key)
+	if owner == n.self {
+		value, ok := n.getLocal(key)
+		return value, ok, nil
+	}
+
+	resp, err := n.do(ctx, http.MethodGet, owner, key, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		value, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read value from %s: %w", owner, err)
+		}
+		return value, true, nil
+	case http.StatusNotFound:
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("%w: %s responded %s", ErrUnexpectedStatus, owner, resp.Status)
+	}
+}
+
+func (n *Node) Set(ctx context.Context, key string, value []byte) error {
+	owner := n.Owner(key)
+	if owner == n.self {
+		n.setLocal(key, value)
+		return nil
+	}
+
+	resp, err := n.do(ctx, http.MethodPut, owner, key, value)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("%w: %s responded %s", ErrUnexpectedStatus, owner, resp.Status)
+	}
+	return nil
+}
+
+// ServeHTTP serves the local part of the cache to peers. Requests are never forwarded further,
+// so nodes with different views of the ring can't bounce a key between each other.
+func (n *Node) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, BasePath) {
+		http.NotFound(w, r)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, BasePath)
+	if key == "" {
+		http.Error(w, "empty key", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		value, ok := n.getLocal(key)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(value)
+	case http.MethodPut:
+		value, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		n.setLocal(key, value)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (n *Node) getLocal(key string) ([]byte, bool) {
+	n.cacheMu.Lock()
+	defer n.cacheMu.Unlock()
+
+	value, ok := n.cache.Get(hw04lrucache.Key(key))
+	if !ok {
+		return nil, false
+	}
+	return value.([]byte), true
+}
+
+func (n *Node) setLocal(key string, value []byte) {
+	n.cacheMu.Lock()
+	defer n.cacheMu.Unlock()
+	n.cache.Set(hw04lrucache.Key(key), value)
+}
+
+func (n *Node) do(ctx context.Context, method, owner, key string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, owner+BasePath+url.PathEscape(key), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request to %s: %w", owner, err)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", owner, err)
+	}
+	return resp, nil
+}
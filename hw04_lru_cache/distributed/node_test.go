@@ -0,0 +1,121 @@
+package distributed
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+
+	hw04lrucache "github.com/a-klimenko/go-otus-hw/hw04_lru_cache"
+	"github.com/stretchr/testify/require"
+)
+
+// startNodes runs count nodes on loopback, each knowing about all the others.
+func startNodes(t *testing.T, count int) []*Node {
+	t.Helper()
+
+	listeners := make([]net.Listener, count)
+	addrs := make([]string, count)
+	for i := range listeners {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		listeners[i] = ln
+		addrs[i] = "http://" + ln.Addr().String()
+	}
+
+	nodes := make([]*Node, count)
+	for i, ln := range listeners {
+		node := NewNode(addrs[i], hw04lrucache.NewCache(1000), 50)
+		node.SetPeers(addrs...)
+		nodes[i] = node
+
+		server := &http.Server{Handler: node}
+		go server.Serve(ln)
+		t.Cleanup(func() { server.Close() })
+	}
+
+	return nodes
+}
+
+func TestNode(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("every node sees values set through any node", func(t *testing.T) {
+		nodes := startNodes(t, 3)
+
+		for i := 0; i < 100; i++ {
+			key := "key-" + strconv.Itoa(i)
+			require.NoError(t, nodes[i%len(nodes)].Set(ctx, key, []byte(strconv.Itoa(i))))
+		}
+
+		for _, node := range nodes {
+			for i := 0; i < 100; i++ {
+				value, ok, err := node.Get(ctx, "key-"+strconv.Itoa(i))
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.Equal(t, strconv.Itoa(i), string(value))
+			}
+		}
+
+		_, ok, err := nodes[0].Get(ctx, "missing")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("values are stored only by owners", func(t *testing.T) {
+		nodes := startNodes(t, 3)
+
+		for i := 0; i < 100; i++ {
+			key := "key-" + strconv.Itoa(i)
+			require.NoError(t, nodes[0].Set(ctx, key, []byte("v")))
+
+			for _, node := range nodes {
+				_, ok := node.getLocal(key)
+				require.Equal(t, node.Owner(key) == node.self, ok)
+			}
+		}
+	})
+
+	t.Run("nodes agree on owners", func(t *testing.T) {
+		nodes := startNodes(t, 4)
+
+		for i := 0; i < 100; i++ {
+			key := "key-" + strconv.Itoa(i)
+			for _, node := range nodes[1:] {
+				require.Equal(t, nodes[0].Owner(key), node.Owner(key))
+			}
+		}
+	})
+
+	t.Run("keys with special characters", func(t *testing.T) {
+		nodes := startNodes(t, 2)
+
+		for _, key := range []string{"a/b", "a b", "a?b=c", "ключ"} {
+			require.NoError(t, nodes[0].Set(ctx, key, []byte(key)))
+			for _, node := range nodes {
+				value, ok, err := node.Get(ctx, key)
+				require.NoError(t, err)
+				require.True(t, ok)
+				require.Equal(t, key, string(value))
+			}
+		}
+	})
+
+	t.Run("unreachable owner", func(t *testing.T) {
+		nodes := startNodes(t, 1)
+		nodes[0].SetPeers("http://127.0.0.1:1")
+
+		for i := 0; i < 100; i++ {
+			key := "key-" + strconv.Itoa(i)
+			if nodes[0].Owner(key) == nodes[0].self {
+				continue
+			}
+			require.Error(t, nodes[0].Set(ctx, key, []byte("v")))
+			_, _, err := nodes[0].Get(ctx, key)
+			require.Error(t, err)
+			return
+		}
+		t.Fatal("no key is owned by the unreachable peer")
+	})
+}
@@ -0,0 +1,98 @@
+package distributed
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// HashFunc maps data to a point on the ring.
+type HashFunc func(data []byte) uint32
+
+// Ring is a consistent-hash ring with virtual nodes. It is not safe for concurrent use.
+type Ring struct {
+	replicas int
+	hash     HashFunc
+	nodes    map[string]struct{}
+	points   []uint32          // sorted hashes of virtual nodes
+	owners   map[uint32]string // virtual node hash -> node
+}
+
+// NewRing creates a ring placing replicas virtual nodes per node. crc32 is used if hash is nil.
+func NewRing(replicas int, hash HashFunc) *Ring {
+	if replicas <= 0 {
+		replicas = 1
+	}
+	if hash == nil {
+		hash = crc32.ChecksumIEEE
+	}
+
+	return &Ring{
+		replicas: replicas,
+		hash:     hash,
+		nodes:    make(map[string]struct{}),
+		owners:   make(map[uint32]string),
+	}
+}
+
+func (r *Ring) Add(nodes ...string) {
+	for _, node := range nodes {
+		r.nodes[node] = struct{}{}
+	}
+	r.rebuild()
+}
+
+func (r *Ring) Remove(nodes ...string) {
+	for _, node := range nodes {
+		delete(r.nodes, node)
+	}
+	r.rebuild()
+}
+
+// Nodes returns the ring members in lexical order.
+func (r *Ring) Nodes() []string {
+	nodes := make([]string, 0, len(r.nodes))
+	for node := range r.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// Get returns the node owning key, or an empty string if the ring is empty.
+func (r *Ring) Get(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	h := r.hash([]byte(key))
+	idx := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if idx == len(r.points) {
+		idx = 0
+	}
+	return r.owners[r.points[idx]]
+}
+
+// rebuild places virtual nodes of all members. Virtual node positions depend only on the node name,
+// so a membership change moves only the keys between the changed node and its ring neighbours.
+func (r *Ring) rebuild() {
+	r.points = r.points[:0]
+	r.owners = make(map[uint32]string, len(r.nodes)*r.replicas)
+
+	for node := range r.nodes {
+		for i := 0; i < r.replicas; i++ {
+			h := r.hash([]byte(node + "#" + strconv.Itoa(i)))
+			// On a collision the lexically smaller node wins, so the result doesn't depend on map order.
+			if owner, ok := r.owners[h]; ok {
+				if node < owner {
+					r.owners[h] = node
+				}
+				continue
+			}
+			r.owners[h] = node
+			r.points = append(r.points, h)
+		}
+	}
+
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+}
@@ -0,0 +1,109 @@
+package distributed
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func ringKeys(count int) []string {
+	keys := make([]string, count)
+	for i := range keys {
+		keys[i] = "key-" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+func owners(r *Ring, keys []string) map[string]string {
+	result := make(map[string]string, len(keys))
+	for _, key := range keys {
+		result[key] = r.Get(key)
+	}
+	return result
+}
+
+func TestRing(t *testing.T) {
+	t.Run("empty ring", func(t *testing.T) {
+		r := NewRing(10, nil)
+		require.Equal(t, "", r.Get("aaa"))
+	})
+
+	t.Run("custom hash", func(t *testing.T) {
+		// Virtual nodes of "a" are placed at 10, 20, 30 and of "b" at 15, 25, 35.
+		hash := func(data []byte) uint32 {
+			positions := map[string]uint32{
+				"a#0": 10, "a#1": 20, "a#2": 30,
+				"b#0": 15, "b#1": 25, "b#2": 35,
+				"k12": 12, "k16": 16, "k30": 30, "k40": 40,
+			}
+			return positions[string(data)]
+		}
+
+		r := NewRing(3, hash)
+		r.Add("a", "b")
+
+		require.Equal(t, "b", r.Get("k12"))
+		require.Equal(t, "a", r.Get("k16"))
+		require.Equal(t, "a", r.Get("k30"))
+		require.Equal(t, "a", r.Get("k40")) // wraps around to the first point
+		require.Equal(t, []string{"a", "b"}, r.Nodes())
+	})
+
+	t.Run("keys are spread between nodes", func(t *testing.T) {
+		r := NewRing(100, nil)
+		r.Add("node-1", "node-2", "node-3", "node-4")
+
+		keys := ringKeys(10_000)
+		counts := make(map[string]int)
+		for _, owner := range owners(r, keys) {
+			counts[owner]++
+		}
+
+		require.Len(t, counts, 4)
+		for node, count := range counts {
+			require.Greaterf(t, count, len(keys)/8, "node %s owns too few keys", node)
+		}
+	})
+
+	t.Run("adding a node moves keys only to it", func(t *testing.T) {
+		r := NewRing(100, nil)
+		r.Add("node-1", "node-2", "node-3")
+
+		keys := ringKeys(10_000)
+		before := owners(r, keys)
+
+		r.Add("node-4")
+		after := owners(r, keys)
+
+		moved := 0
+		for _, key := range keys {
+			if before[key] != after[key] {
+				moved++
+				require.Equal(t, "node-4", after[key])
+			}
+		}
+		require.Greater(t, moved, 0)
+		require.Less(t, moved, len(keys)/2, "far more than 1/4 of keys moved")
+	})
+
+	t.Run("removing a node moves only its keys", func(t *testing.T) {
+		r := NewRing(100, nil)
+		r.Add("node-1", "node-2", "node-3", "node-4")
+
+		keys := ringKeys(10_000)
+		before := owners(r, keys)
+
+		r.Remove("node-2")
+		after := owners(r, keys)
+
+		for _, key := range keys {
+			if before[key] != "node-2" {
+				require.Equal(t, before[key], after[key])
+			} else {
+				require.NotEqual(t, "node-2", after[key])
+			}
+		}
+		require.Equal(t, []string{"node-1", "node-3", "node-4"}, r.Nodes())
+	})
+}
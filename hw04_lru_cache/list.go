@@ -6,14 +6,24 @@ type List interface {
 	Back() *ListItem
 	PushFront(v interface{}) *ListItem
 	PushBack(v interface{}) *ListItem
-	Remove(i *ListItem)
+	PushFrontList(other List)
+	PushBackList(other List)
+	InsertBefore(v interface{}, mark *ListItem) *ListItem
+	InsertAfter(v interface{}, mark *ListItem) *ListItem
+	Remove(i *ListItem) interface{}
 	MoveToFront(i *ListItem)
+	MoveToBack(i *ListItem)
+	MoveBefore(i, mark *ListItem)
+	MoveAfter(i, mark *ListItem)
+	ForEach(fn func(i *ListItem) bool)
+	ForEachBackward(fn func(i *ListItem) bool)
 }
 
 type ListItem struct {
 	Value interface{}
 	Next  *ListItem
 	Prev  *ListItem
+	list  *list // the list the item belongs to, nil for removed items
 }
 
 type list struct {
@@ -35,58 +45,141 @@ func (l list) Back() *ListItem {
 }
 
 func (l *list) PushFront(v interface{}) *ListItem {
-	l.Count++
-	newNode := &ListItem{Value: v}
+	return l.link(&ListItem{Value: v}, nil, l.First)
+}
 
-	if l.First != nil {
-		newNode.Next = l.First
-		l.First.Prev = newNode
+func (l *list) PushBack(v interface{}) *ListItem {
+	return l.link(&ListItem{Value: v}, l.Last, nil)
+}
+
+// PushFrontList inserts a copy of other at the front of the list. The lists may be the same.
+func (l *list) PushFrontList(other List) {
+	for n, i := other.Len(), other.Back(); n > 0; n, i = n-1, i.Prev {
+		l.PushFront(i.Value)
 	}
+}
 
-	if l.Last == nil {
-		l.Last = newNode
+// PushBackList inserts a copy of other at the back of the list. The lists may be the same.
+func (l *list) PushBackList(other List) {
+	for n, i := other.Len(), other.Front(); n > 0; n, i = n-1, i.Next {
+		l.PushBack(i.Value)
 	}
+}
 
-	l.First = newNode
-	return newNode
+// InsertBefore inserts v right before mark and returns the new item, or nil if mark is not in the list.
+func (l *list) InsertBefore(v interface{}, mark *ListItem) *ListItem {
+	if !l.owns(mark) {
+		return nil
+	}
+	return l.link(&ListItem{Value: v}, mark.Prev, mark)
 }
 
-func (l *list) PushBack(v interface{}) *ListItem {
-	l.Count++
-	newNode := &ListItem{Value: v}
+// InsertAfter inserts v right after mark and returns the new item, or nil if mark is not in the list.
+func (l *list) InsertAfter(v interface{}, mark *ListItem) *ListItem {
+	if !l.owns(mark) {
+		return nil
+	}
+	return l.link(&ListItem{Value: v}, mark, mark.Next)
+}
 
-	if l.Last != nil {
-		newNode.Prev = l.Last
-		l.Last.Next = newNode
+// Remove removes i from the list if it belongs to it and returns its value.
+func (l *list) Remove(i *ListItem) interface{} {
+	if l.owns(i) {
+		l.unlink(i)
+		i.list = nil
 	}
+	return i.Value
+}
 
-	if l.First == nil {
-		l.First = newNode
+func (l *list) MoveToFront(i *ListItem) {
+	if !l.owns(i) || l.First == i {
+		return
 	}
+	l.unlink(i)
+	l.link(i, nil, l.First)
+}
 
-	l.Last = newNode
-	return newNode
+func (l *list) MoveToBack(i *ListItem) {
+	if !l.owns(i) || l.Last == i {
+		return
+	}
+	l.unlink(i)
+	l.link(i, l.Last, nil)
 }
 
-func (l *list) Remove(i *ListItem) {
-	l.Count--
+// MoveBefore moves i right before mark. Nothing happens if either of them is not in the list.
+func (l *list) MoveBefore(i, mark *ListItem) {
+	if !l.owns(i) || !l.owns(mark) || i == mark {
+		return
+	}
+	l.unlink(i)
+	l.link(i, mark.Prev, mark)
+}
 
-	if i.Prev == nil {
-		l.First = i.Next
+// MoveAfter moves i right after mark. Nothing happens if either of them is not in the list.
+func (l *list) MoveAfter(i, mark *ListItem) {
+	if !l.owns(i) || !l.owns(mark) || i == mark {
+		return
+	}
+	l.unlink(i)
+	l.link(i, mark, mark.Next)
+}
+
+// ForEach calls fn for every item from front to back until fn returns false.
+// The current item may be safely removed by fn.
+func (l *list) ForEach(fn func(i *ListItem) bool) {
+	for i := l.First; i != nil; {
+		next := i.Next
+		if !fn(i) {
+			return
+		}
+		i = next
+	}
+}
+
+// ForEachBackward calls fn for every item from back to front until fn returns false.
+// The current item may be safely removed by fn.
+func (l *list) ForEachBackward(fn func(i *ListItem) bool) {
+	for i := l.Last; i != nil; {
+		prev := i.Prev
+		if !fn(i) {
+			return
+		}
+		i = prev
+	}
+}
+
+func (l *list) owns(i *ListItem) bool {
+	return i != nil && i.list == l
+}
+
+// link inserts the detached item i between prev and next, any of which may be nil at the list edges.
+func (l *list) link(i, prev, next *ListItem) *ListItem {
+	i.list = l
+	i.Prev = prev
+	i.Next = next
+
+	if prev == nil {
+		l.First = i
 	} else {
-		i.Prev.Next = i.Next
+		prev.Next = i
 	}
 
-	if i.Next == nil {
-		l.Last = i.Prev
+	if next == nil {
+		l.Last = i
 	} else {
-		i.Next.Prev = i.Prev
+		next.Prev = i
 	}
+
+	l.Count++
+	return i
 }
 
-func (l *list) MoveToFront(i *ListItem) {
+func (l *list) unlink(i *ListItem) {
 	if i.Prev == nil {
-		return
+		l.First = i.Next
+	} else {
+		i.Prev.Next = i.Next
 	}
 
 	if i.Next == nil {
@@ -95,11 +188,9 @@ func (l *list) MoveToFront(i *ListItem) {
 		i.Next.Prev = i.Prev
 	}
 
-	i.Prev.Next = i.Next
 	i.Prev = nil
-	i.Next = l.First
-	l.First.Prev = i
-	l.First = i
+	i.Next = nil
+	l.Count--
 }
 
 func NewList() List {
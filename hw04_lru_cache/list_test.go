@@ -49,4 +49,124 @@ func TestList(t *testing.T) {
 		}
 		require.Equal(t, []int{70, 60, 80, 40, 10, 30, 50}, elems)
 	})
+
+	t.Run("insert and move around marks", func(t *testing.T) {
+		l := NewList()
+
+		twenty := l.PushBack(20)          // [20]
+		ten := l.InsertBefore(10, twenty) // [10, 20]
+		thirty := l.InsertAfter(30, twenty)
+		l.InsertAfter(40, thirty) // [10, 20, 30, 40]
+		require.Equal(t, []int{10, 20, 30, 40}, values(l))
+
+		l.MoveToBack(ten) // [20, 30, 40, 10]
+		l.MoveToBack(ten) // [20, 30, 40, 10]
+		require.Equal(t, []int{20, 30, 40, 10}, values(l))
+
+		l.MoveBefore(ten, twenty) // [10, 20, 30, 40]
+		require.Equal(t, []int{10, 20, 30, 40}, values(l))
+
+		l.MoveAfter(ten, thirty) // [20, 30, 10, 40]
+		require.Equal(t, []int{20, 30, 10, 40}, values(l))
+
+		l.MoveBefore(ten, ten)
+		l.MoveAfter(thirty, thirty)
+		require.Equal(t, []int{20, 30, 10, 40}, values(l))
+		require.Equal(t, []int{40, 10, 30, 20}, valuesBackward(l))
+		require.Equal(t, 4, l.Len())
+	})
+
+	t.Run("push lists", func(t *testing.T) {
+		l := NewList()
+		l.PushBack(1)
+		l.PushBack(2)
+
+		other := NewList()
+		other.PushBack(3)
+		other.PushBack(4)
+
+		l.PushBackList(other)  // [1, 2, 3, 4]
+		l.PushFrontList(other) // [3, 4, 1, 2, 3, 4]
+		require.Equal(t, []int{3, 4, 1, 2, 3, 4}, values(l))
+
+		other.PushBackList(other) // [3, 4, 3, 4]
+		other.PushFrontList(other)
+		require.Equal(t, []int{3, 4, 3, 4, 3, 4, 3, 4}, values(other))
+		require.Equal(t, 8, other.Len())
+	})
+
+	t.Run("foreign items are rejected", func(t *testing.T) {
+		l := NewList()
+		mine := l.PushBack(10)
+		l.PushBack(20)
+
+		other := NewList()
+		foreign := other.PushBack(30)
+
+		require.Equal(t, 30, l.Remove(foreign))
+		require.Equal(t, 2, l.Len())
+		require.Equal(t, 1, other.Len())
+
+		l.MoveToFront(foreign)
+		l.MoveToBack(foreign)
+		l.MoveBefore(foreign, mine)
+		l.MoveAfter(mine, foreign)
+		require.Nil(t, l.InsertBefore(40, foreign))
+		require.Nil(t, l.InsertAfter(40, foreign))
+		require.Equal(t, []int{10, 20}, values(l))
+		require.Equal(t, []int{30}, values(other))
+
+		require.Equal(t, 10, l.Remove(mine))
+		require.Equal(t, 10, l.Remove(mine)) // removing twice doesn't break the count
+		require.Equal(t, 1, l.Len())
+		require.Nil(t, l.InsertAfter(40, mine))
+	})
+
+	t.Run("iteration", func(t *testing.T) {
+		l := NewList()
+		for i := 1; i <= 5; i++ {
+			l.PushBack(i)
+		}
+
+		visited := make([]int, 0, 2)
+		l.ForEach(func(i *ListItem) bool {
+			visited = append(visited, i.Value.(int))
+			return len(visited) < 2
+		})
+		require.Equal(t, []int{1, 2}, visited)
+
+		l.ForEach(func(i *ListItem) bool {
+			if i.Value.(int)%2 == 0 {
+				l.Remove(i)
+			}
+			return true
+		})
+		require.Equal(t, []int{1, 3, 5}, values(l))
+
+		l.ForEachBackward(func(i *ListItem) bool {
+			if i.Value.(int) == 5 {
+				l.Remove(i)
+			}
+			return true
+		})
+		require.Equal(t, []int{3, 1}, valuesBackward(l))
+	})
+}
+
+func values(l List) []int {
+	elems := make([]int, 0, l.Len())
+	l.ForEach(func(i *ListItem) bool {
+		elems = append(elems, i.Value.(int))
+		return true
+	})
+	return elems
+}
+
+func valuesBackward(l List) []int {
+	elems := make([]int, 0, l.Len())
+	l.ForEachBackward(func(i *ListItem) bool {
+		elems = append(elems, i.Value.(int))
+		return true
+	})
+	return elems
 }
@@ -0,0 +1,98 @@
+package hw05parallelexecution
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxErrorsInMessage limits how many task errors are listed by RunErrors.Error.
+const maxErrorsInMessage = 5
+
+// TaskError is a failure of the task at position Index of the input.
+type TaskError struct {
+	Index int
+	Err   error
+}
+
+func (e TaskError) Error() string {
+	return fmt.Sprintf("task %d: %v", e.Index, e.Err)
+}
+
+func (e TaskError) Unwrap() error {
+	return e.Err
+}
+
+// RunErrors describes the outcome of a run that was stopped or had failed tasks.
+// errors.Is and errors.As look through both Cause and every task error.
+type RunErrors struct {
+	// Cause is the reason the run was stopped: ErrErrorsLimitExceeded, a context error or nil.
+	Cause error
+	// Failed lists the failed tasks ordered by index.
+	Failed []TaskError
+	// NotStarted lists, in ascending order, indices of tasks that were never started.
+	NotStarted []int
+	// Skipped lists, in ascending order, indices of graph tasks skipped because a dependency failed.
+	Skipped []int
+}
+
+func newRunErrors(cause error, failed []TaskError, notStarted, skipped []int) *RunErrors {
+	sort.Slice(failed, func(i, j int) bool { return failed[i].Index < failed[j].Index })
+	sort.Ints(notStarted)
+	sort.Ints(skipped)
+
+	return &RunErrors{
+		Cause:      cause,
+		Failed:     failed,
+		NotStarted: notStarted,
+		Skipped:    skipped,
+	}
+}
+
+func (e *RunErrors) Error() string {
+	var sb strings.Builder
+	if e.Cause != nil {
+		sb.WriteString(e.Cause.Error())
+		sb.WriteString(": ")
+	}
+	fmt.Fprintf(&sb, "%d tasks failed, %d not started", len(e.Failed), len(e.NotStarted))
+	if len(e.Skipped) > 0 {
+		fmt.Fprintf(&sb, ", %d skipped", len(e.Skipped))
+	}
+
+	for i, failed := range e.Failed {
+		if i == maxErrorsInMessage {
+			fmt.Fprintf(&sb, "; and %d more", len(e.Failed)-maxErrorsInMessage)
+			break
+		}
+		sb.WriteString("; ")
+		sb.WriteString(failed.Error())
+	}
+
+	return sb.String()
+}
+
+func (e *RunErrors) Is(target error) bool {
+	if e.Cause != nil && errors.Is(e.Cause, target) {
+		return true
+	}
+	for _, failed := range e.Failed {
+		if errors.Is(failed, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *RunErrors) As(target interface{}) bool {
+	if e.Cause != nil && errors.As(e.Cause, target) {
+		return true
+	}
+	for _, failed := range e.Failed {
+		if errors.As(failed, target) {
+			return true
+		}
+	}
+	return false
+}
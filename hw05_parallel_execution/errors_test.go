@@ -0,0 +1,142 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestRunErrors(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("errors limit keeps failed and not started tasks", func(t *testing.T) {
+		tasksCount := 100
+		tasks := make([]ContextTask, 0, tasksCount)
+
+		for i := 0; i < tasksCount; i++ {
+			err := fmt.Errorf("error from task %d", i)
+			tasks = append(tasks, func(ctx context.Context) error {
+				return err
+			})
+		}
+
+		err := RunContext(context.Background(), tasks, Options{Workers: 1, MaxErrors: 3})
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+
+		var runErrs *RunErrors
+		require.ErrorAs(t, err, &runErrs)
+		require.ErrorIs(t, runErrs.Cause, ErrErrorsLimitExceeded)
+		require.Len(t, runErrs.Failed, 3)
+		for i, failed := range runErrs.Failed {
+			require.Equal(t, i, failed.Index)
+			require.EqualError(t, failed.Err, fmt.Sprintf("error from task %d", i))
+		}
+
+		// With a single worker the task right after the limit may still be handed over, but not run.
+		require.Len(t, runErrs.NotStarted, tasksCount-3)
+		require.Equal(t, 3, runErrs.NotStarted[0])
+		require.Equal(t, tasksCount-1, runErrs.NotStarted[len(runErrs.NotStarted)-1])
+	})
+
+	t.Run("failures below the limit are reported on request", func(t *testing.T) {
+		tasks := []ContextTask{
+			func(ctx context.Context) error { return nil },
+			func(ctx context.Context) error { return io.EOF },
+			func(ctx context.Context) error { return nil },
+			func(ctx context.Context) error { return &os.PathError{Op: "open", Path: "x", Err: os.ErrNotExist} },
+		}
+
+		err := RunContext(context.Background(), tasks, Options{Workers: 2, MaxErrors: 10})
+		require.NoError(t, err)
+
+		err = RunContext(context.Background(), tasks, Options{Workers: 2, MaxErrors: 10, ReportFailures: true})
+		require.Error(t, err)
+		require.False(t, errors.Is(err, ErrErrorsLimitExceeded))
+		require.ErrorIs(t, err, io.EOF)
+		require.ErrorIs(t, err, os.ErrNotExist)
+
+		var pathErr *os.PathError
+		require.ErrorAs(t, err, &pathErr)
+		require.Equal(t, "x", pathErr.Path)
+
+		var taskErr TaskError
+		require.ErrorAs(t, err, &taskErr)
+		require.Equal(t, 1, taskErr.Index)
+
+		var runErrs *RunErrors
+		require.ErrorAs(t, err, &runErrs)
+		require.Nil(t, runErrs.Cause)
+		require.Empty(t, runErrs.NotStarted)
+		require.Equal(t, []int{1, 3}, []int{runErrs.Failed[0].Index, runErrs.Failed[1].Index})
+	})
+
+	t.Run("Run drops failures below the limit, RunReport keeps them", func(t *testing.T) {
+		tasks := []Task{
+			func() error { return nil },
+			func() error { return io.EOF },
+		}
+
+		require.NoError(t, Run(tasks, 2, 10))
+
+		err := RunReport(tasks, 2, 10)
+		require.ErrorIs(t, err, io.EOF)
+		require.False(t, errors.Is(err, ErrErrorsLimitExceeded))
+
+		var runErrs *RunErrors
+		require.ErrorAs(t, err, &runErrs)
+		require.Len(t, runErrs.Failed, 1)
+		require.Equal(t, 1, runErrs.Failed[0].Index)
+
+		require.NoError(t, RunReport(tasks[:1], 2, 10))
+	})
+
+	t.Run("ignored errors are reported on request", func(t *testing.T) {
+		tasks := []ContextTask{
+			func(ctx context.Context) error { return io.EOF },
+			func(ctx context.Context) error { return io.EOF },
+		}
+
+		err := RunContext(context.Background(), tasks, Options{Workers: 1, ReportFailures: true})
+
+		var runErrs *RunErrors
+		require.ErrorAs(t, err, &runErrs)
+		require.Len(t, runErrs.Failed, 2)
+	})
+
+	t.Run("cancelled run reports not started tasks", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		tasks := []ContextTask{
+			func(ctx context.Context) error {
+				cancel()
+				return nil
+			},
+			func(ctx context.Context) error { return nil },
+			func(ctx context.Context) error { return nil },
+		}
+
+		err := RunContext(ctx, tasks, Options{Workers: 1})
+		require.ErrorIs(t, err, context.Canceled)
+
+		var runErrs *RunErrors
+		require.ErrorAs(t, err, &runErrs)
+		require.Empty(t, runErrs.Failed)
+		require.Equal(t, []int{1, 2}, runErrs.NotStarted)
+	})
+
+	t.Run("error message", func(t *testing.T) {
+		failed := make([]TaskError, 0, 7)
+		for i := 0; i < 7; i++ {
+			failed = append(failed, TaskError{Index: i, Err: io.EOF})
+		}
+
+		err := newRunErrors(ErrErrorsLimitExceeded, failed, []int{7, 8}, nil)
+		require.EqualError(t, err, "errors limit exceeded: 7 tasks failed, 2 not started; "+
+			"task 0: EOF; task 1: EOF; task 2: EOF; task 3: EOF; task 4: EOF; and 2 more")
+	})
+}
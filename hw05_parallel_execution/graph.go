@@ -0,0 +1,207 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrDuplicateTask     = errors.New("duplicate task")
+	ErrUnknownDependency = errors.New("unknown dependency")
+	ErrDependencyCycle   = errors.New("dependency cycle")
+)
+
+// Graph is a set of named tasks with dependencies between them.
+// Indices in *RunErrors returned by Run are the order in which tasks were added.
+type Graph struct {
+	names   []string
+	tasks   []ContextTask
+	deps    [][]string
+	indices map[string]int
+}
+
+func NewGraph() *Graph {
+	return &Graph{indices: make(map[string]int)}
+}
+
+// Add registers a task that may start only after all of deps have succeeded.
+// Dependencies may be added later, they are resolved by Run.
+func (g *Graph) Add(name string, task ContextTask, deps ...string) error {
+	if _, ok := g.indices[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateTask, name)
+	}
+
+	g.indices[name] = len(g.names)
+	g.names = append(g.names, name)
+	g.tasks = append(g.tasks, task)
+	g.deps = append(g.deps, deps)
+	return nil
+}
+
+// Name returns the name of the task with the given index.
+func (g *Graph) Name(index int) string {
+	return g.names[index]
+}
+
+// Run executes tasks in opts.Workers goroutines in topological order. Dependents of a failed task
+// are skipped, opts.MaxErrors counts failed tasks only. If any task was skipped, the error is *RunErrors
+// even below the limit. The graph is validated before anything starts.
+func (g *Graph) Run(ctx context.Context, opts Options) error {
+	if opts.Workers <= 0 {
+		return ErrNoWorkers
+	}
+
+	dependents, indegree, err := g.resolve()
+	if err != nil {
+		return err
+	}
+
+	r := newRunner(ctx, opts)
+	defer r.cancel()
+
+	type outcome struct {
+		index   int
+		started bool
+		err     error
+	}
+
+	jobCh := make(chan job)
+	outcomes := make(chan outcome)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			defer r.observeWorker(id)()
+			for j := range jobCh {
+				started, err := r.execute(j)
+				outcomes <- outcome{index: j.index, started: started, err: err}
+			}
+		}(i)
+	}
+
+	decided := make([]bool, len(g.tasks)) // started, skipped or reported as not started
+	ready := make([]int, 0, len(g.tasks))
+	for i, degree := range indegree {
+		if degree == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	inFlight := 0
+	for {
+		var send chan<- job
+		var stop <-chan struct{}
+		var next job
+		if len(ready) > 0 && r.runCtx.Err() == nil {
+			send = jobCh
+			stop = r.runCtx.Done()
+			next = job{index: ready[0], task: g.tasks[ready[0]]}
+		}
+		if send == nil && inFlight == 0 {
+			break
+		}
+
+		select {
+		case send <- next:
+			decided[next.index] = true
+			ready = ready[1:]
+			inFlight++
+		case o := <-outcomes:
+			inFlight--
+			switch {
+			case !o.started:
+				// Already reported as not started, its dependents will be reported below.
+			case o.err != nil:
+				r.skipped = append(r.skipped, g.skipDependents(o.index, dependents, decided)...)
+			default:
+				for _, d := range dependents[o.index] {
+					if indegree[d]--; indegree[d] == 0 && !decided[d] {
+						ready = append(ready, d)
+					}
+				}
+			}
+		case <-stop:
+			// Stop feeding, keep collecting outcomes of running tasks.
+		}
+	}
+	close(jobCh)
+	wg.Wait()
+
+	for i := range g.tasks {
+		if !decided[i] {
+			r.notStarted = append(r.notStarted, i)
+		}
+	}
+
+	return r.finish()
+}
+
+// skipDependents marks all transitive dependents of a failed task as decided and returns them.
+func (g *Graph) skipDependents(failed int, dependents [][]int, decided []bool) []int {
+	var skipped []int
+	stack := append([]int(nil), dependents[failed]...)
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if decided[i] {
+			continue
+		}
+
+		decided[i] = true
+		skipped = append(skipped, i)
+		stack = append(stack, dependents[i]...)
+	}
+	return skipped
+}
+
+// resolve builds the reverse edges and checks that every dependency exists and there are no cycles.
+func (g *Graph) resolve() ([][]int, []int, error) {
+	dependents := make([][]int, len(g.tasks))
+	indegree := make([]int, len(g.tasks))
+
+	for i, deps := range g.deps {
+		for _, dep := range deps {
+			d, ok := g.indices[dep]
+			if !ok {
+				return nil, nil, fmt.Errorf("%w: %s depends on %s", ErrUnknownDependency, g.names[i], dep)
+			}
+			dependents[d] = append(dependents[d], i)
+			indegree[i]++
+		}
+	}
+
+	// Kahn's algorithm: whatever can't be ordered belongs to a cycle or depends on one.
+	remaining := append([]int(nil), indegree...)
+	queue := make([]int, 0, len(g.tasks))
+	for i, degree := range remaining {
+		if degree == 0 {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, d := range dependents[i] {
+			if remaining[d]--; remaining[d] == 0 {
+				queue = append(queue, d)
+			}
+		}
+	}
+
+	var cyclic []string
+	for i, degree := range remaining {
+		if degree > 0 {
+			cyclic = append(cyclic, g.names[i])
+		}
+	}
+	if len(cyclic) > 0 {
+		return nil, nil, fmt.Errorf("%w among tasks: %s", ErrDependencyCycle, strings.Join(cyclic, ", "))
+	}
+
+	return dependents, indegree, nil
+}
@@ -0,0 +1,198 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// recorder keeps the order in which graph tasks were run.
+type recorder struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (r *recorder) task(name string, err error) ContextTask {
+	return func(ctx context.Context) error {
+		r.mu.Lock()
+		r.order = append(r.order, name)
+		r.mu.Unlock()
+		return err
+	}
+}
+
+func (r *recorder) position(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, n := range r.order {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestGraph(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	ctx := context.Background()
+
+	t.Run("tasks run in topological order", func(t *testing.T) {
+		rec := &recorder{}
+		g := NewGraph()
+		// Dependencies are declared before the tasks they refer to on purpose.
+		require.NoError(t, g.Add("deploy", rec.task("deploy", nil), "test", "lint"))
+		require.NoError(t, g.Add("test", rec.task("test", nil), "build"))
+		require.NoError(t, g.Add("lint", rec.task("lint", nil), "build"))
+		require.NoError(t, g.Add("build", rec.task("build", nil), "fetch"))
+		require.NoError(t, g.Add("fetch", rec.task("fetch", nil)))
+		require.NoError(t, g.Add("docs", rec.task("docs", nil)))
+
+		require.NoError(t, g.Run(ctx, Options{Workers: 3, MaxErrors: 1}))
+
+		require.Len(t, rec.order, 6)
+		require.Less(t, rec.position("fetch"), rec.position("build"))
+		require.Less(t, rec.position("build"), rec.position("test"))
+		require.Less(t, rec.position("build"), rec.position("lint"))
+		require.Less(t, rec.position("test"), rec.position("deploy"))
+		require.Less(t, rec.position("lint"), rec.position("deploy"))
+	})
+
+	t.Run("independent tasks run concurrently", func(t *testing.T) {
+		var started int32
+		barrier := func(ctx context.Context) error {
+			atomic.AddInt32(&started, 1)
+			for atomic.LoadInt32(&started) < 2 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Millisecond):
+				}
+			}
+			return nil
+		}
+
+		g := NewGraph()
+		require.NoError(t, g.Add("root", func(ctx context.Context) error { return nil }))
+		require.NoError(t, g.Add("left", barrier, "root"))
+		require.NoError(t, g.Add("right", barrier, "root"))
+
+		require.NoError(t, g.Run(ctx, Options{Workers: 2, TaskTimeout: time.Second}))
+	})
+
+	t.Run("dependents of a failed task are skipped", func(t *testing.T) {
+		rec := &recorder{}
+		g := NewGraph()
+		require.NoError(t, g.Add("a", rec.task("a", errors.New("failure"))))
+		require.NoError(t, g.Add("b", rec.task("b", nil), "a"))
+		require.NoError(t, g.Add("c", rec.task("c", nil), "b"))
+		require.NoError(t, g.Add("d", rec.task("d", nil)))
+		require.NoError(t, g.Add("e", rec.task("e", nil), "d", "c"))
+
+		// Skipped tasks are reported below the limit.
+		err := g.Run(ctx, Options{Workers: 2, MaxErrors: 5})
+		var runErrs *RunErrors
+		require.ErrorAs(t, err, &runErrs)
+		require.Nil(t, runErrs.Cause)
+		require.Equal(t, []int{1, 2, 4}, runErrs.Skipped)
+
+		err = g.Run(ctx, Options{Workers: 2, MaxErrors: 2, ReportFailures: true})
+		require.ErrorAs(t, err, &runErrs)
+		require.Nil(t, runErrs.Cause)
+		require.Len(t, runErrs.Failed, 1)
+		require.Equal(t, "a", g.Name(runErrs.Failed[0].Index))
+		require.Equal(t, []int{1, 2, 4}, runErrs.Skipped)
+		require.Empty(t, runErrs.NotStarted)
+		require.Contains(t, err.Error(), "3 skipped")
+
+		require.Equal(t, -1, rec.position("b"))
+		require.Equal(t, -1, rec.position("e"))
+		require.NotEqual(t, -1, rec.position("d"))
+	})
+
+	t.Run("errors limit keeps its meaning", func(t *testing.T) {
+		g := NewGraph()
+		require.NoError(t, g.Add("a", func(ctx context.Context) error { return errors.New("failure") }))
+		require.NoError(t, g.Add("b", func(ctx context.Context) error { return errors.New("failure") }, "a"))
+		require.NoError(t, g.Add("c", func(ctx context.Context) error { return errors.New("failure") }))
+		require.NoError(t, g.Add("d", func(ctx context.Context) error { return nil }, "c"))
+
+		// b is skipped rather than failed, so only two tasks can fail.
+		err := g.Run(ctx, Options{Workers: 1, MaxErrors: 3})
+		require.NotErrorIs(t, err, ErrErrorsLimitExceeded)
+		var runErrs *RunErrors
+		require.ErrorAs(t, err, &runErrs)
+		require.Len(t, runErrs.Failed, 2)
+		require.Equal(t, []int{1, 3}, runErrs.Skipped)
+
+		err = g.Run(ctx, Options{Workers: 1, MaxErrors: 1})
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+
+		require.ErrorAs(t, err, &runErrs)
+		require.Len(t, runErrs.Failed, 1)
+		require.Equal(t, len(g.names), len(runErrs.Failed)+len(runErrs.Skipped)+len(runErrs.NotStarted))
+	})
+
+	t.Run("cancellation stops scheduling", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		g := NewGraph()
+		require.NoError(t, g.Add("a", func(ctx context.Context) error {
+			cancel()
+			return nil
+		}))
+		require.NoError(t, g.Add("b", func(ctx context.Context) error {
+			t.Error("task was started after cancellation")
+			return nil
+		}, "a"))
+
+		err := g.Run(cancelCtx, Options{Workers: 2})
+		require.ErrorIs(t, err, context.Canceled)
+
+		var runErrs *RunErrors
+		require.ErrorAs(t, err, &runErrs)
+		require.Equal(t, []int{1}, runErrs.NotStarted)
+	})
+
+	t.Run("cycles are detected up front", func(t *testing.T) {
+		var started int32
+		task := func(ctx context.Context) error {
+			atomic.AddInt32(&started, 1)
+			return nil
+		}
+
+		g := NewGraph()
+		require.NoError(t, g.Add("free", task))
+		require.NoError(t, g.Add("a", task, "c"))
+		require.NoError(t, g.Add("b", task, "a", "free"))
+		require.NoError(t, g.Add("c", task, "b"))
+		require.NoError(t, g.Add("tail", task, "c"))
+
+		err := g.Run(ctx, Options{Workers: 2})
+		require.ErrorIs(t, err, ErrDependencyCycle)
+		require.EqualError(t, err, "dependency cycle among tasks: a, b, c, tail")
+		require.Equal(t, int32(0), started)
+	})
+
+	t.Run("self dependency is a cycle", func(t *testing.T) {
+		g := NewGraph()
+		require.NoError(t, g.Add("a", func(ctx context.Context) error { return nil }, "a"))
+		require.ErrorIs(t, g.Run(ctx, Options{Workers: 1}), ErrDependencyCycle)
+	})
+
+	t.Run("invalid graphs", func(t *testing.T) {
+		task := func(ctx context.Context) error { return nil }
+
+		g := NewGraph()
+		require.NoError(t, g.Add("a", task, "missing"))
+		require.ErrorIs(t, g.Add("a", task), ErrDuplicateTask)
+		require.ErrorIs(t, g.Run(ctx, Options{Workers: 1}), ErrUnknownDependency)
+		require.ErrorIs(t, g.Run(ctx, Options{}), ErrNoWorkers)
+		require.NoError(t, NewGraph().Run(ctx, Options{Workers: 1}))
+	})
+}
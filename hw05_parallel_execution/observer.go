@@ -0,0 +1,202 @@
+package hw05parallelexecution
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Observer is notified about the lifecycle of a run. Methods are called from the workers concurrently,
+// so implementations must be safe for concurrent use and should return quickly.
+type Observer interface {
+	// TaskStarted is called right before the task at index is run.
+	TaskStarted(index int)
+	// TaskFinished is called when the task at index has returned, with the time it took including retries.
+	TaskFinished(index int, duration time.Duration, err error)
+	// WorkerStarted and WorkerStopped are called by every worker goroutine, ids start from 0.
+	WorkerStarted(id int)
+	WorkerStopped(id int)
+	// LimitReached is called once when the run is stopped by an error limit, err is the limit error.
+	LimitReached(err error)
+}
+
+// DefaultLatencyBuckets are the upper bounds of latency histograms used by NewMetrics if none are given.
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// histogram counts observations into buckets with the given upper bounds.
+type histogram struct {
+	counts []uint64 // counts[i] is the number of observations in (bounds[i-1], bounds[i]], the last one is +Inf
+	sum    time.Duration
+	count  uint64
+}
+
+// Metrics is an Observer keeping counters and latency histograms of tasks.
+// A single Metrics may be shared by several runs.
+type Metrics struct {
+	bounds []time.Duration
+
+	mu            sync.Mutex
+	inFlight      int
+	completed     uint64
+	failed        uint64
+	workers       int
+	limitsReached uint64
+	succeeded     histogram
+	errored       histogram
+}
+
+// NewMetrics creates Metrics with latency histograms bucketed by the upper bounds,
+// DefaultLatencyBuckets if none are given.
+func NewMetrics(bounds ...time.Duration) *Metrics {
+	if len(bounds) == 0 {
+		bounds = DefaultLatencyBuckets
+	}
+	sorted := make([]time.Duration, len(bounds))
+	copy(sorted, bounds)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &Metrics{
+		bounds:    sorted,
+		succeeded: histogram{counts: make([]uint64, len(sorted)+1)},
+		errored:   histogram{counts: make([]uint64, len(sorted)+1)},
+	}
+}
+
+func (m *Metrics) TaskStarted(int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight++
+}
+
+func (m *Metrics) TaskFinished(_ int, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight--
+	h := &m.succeeded
+	if err != nil {
+		m.failed++
+		h = &m.errored
+	} else {
+		m.completed++
+	}
+
+	h.counts[sort.Search(len(m.bounds), func(i int) bool { return duration <= m.bounds[i] })]++
+	h.sum += duration
+	h.count++
+}
+
+func (m *Metrics) WorkerStarted(int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workers++
+}
+
+func (m *Metrics) WorkerStopped(int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workers--
+}
+
+func (m *Metrics) LimitReached(error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limitsReached++
+}
+
+// InFlight returns the number of running tasks.
+func (m *Metrics) InFlight() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.inFlight
+}
+
+// Completed returns the number of tasks finished without error.
+func (m *Metrics) Completed() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.completed
+}
+
+// Failed returns the number of tasks finished with error.
+func (m *Metrics) Failed() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.failed
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	cw := &countingWriter{w: bw}
+
+	writeMetric(cw, "run_tasks_in_flight", "gauge", "Number of running tasks.", int64(m.inFlight))
+	writeMetric(cw, "run_tasks_completed_total", "counter", "Number of tasks finished without error.",
+		int64(m.completed))
+	writeMetric(cw, "run_tasks_failed_total", "counter", "Number of tasks finished with error.", int64(m.failed))
+	writeMetric(cw, "run_workers", "gauge", "Number of running workers.", int64(m.workers))
+	writeMetric(cw, "run_limits_reached_total", "counter", "Number of runs stopped by an error limit.",
+		int64(m.limitsReached))
+
+	fmt.Fprintln(cw, "# HELP run_task_duration_seconds Duration of tasks including retries.")
+	fmt.Fprintln(cw, "# TYPE run_task_duration_seconds histogram")
+	m.writeHistogram(cw, "ok", &m.succeeded)
+	m.writeHistogram(cw, "error", &m.errored)
+
+	if cw.err == nil {
+		cw.err = bw.Flush()
+	}
+	return cw.n, cw.err
+}
+
+func writeMetric(w io.Writer, name, kind, help string, value int64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, kind, name, value)
+}
+
+func (m *Metrics) writeHistogram(w io.Writer, status string, h *histogram) {
+	var cumulative uint64
+	for i, bound := range m.bounds {
+		cumulative += h.counts[i]
+		le := strconv.FormatFloat(bound.Seconds(), 'g', -1, 64)
+		fmt.Fprintf(w, "run_task_duration_seconds_bucket{status=%q,le=%q} %d\n", status, le, cumulative)
+	}
+	fmt.Fprintf(w, "run_task_duration_seconds_bucket{status=%q,le=\"+Inf\"} %d\n", status, h.count)
+	fmt.Fprintf(w, "run_task_duration_seconds_sum{status=%q} %s\n", status,
+		strconv.FormatFloat(h.sum.Seconds(), 'g', -1, 64))
+	fmt.Fprintf(w, "run_task_duration_seconds_count{status=%q} %d\n", status, h.count)
+}
+
+// countingWriter remembers the number of written bytes and the first error, so the output
+// may be written without checking every call.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	w.err = err
+	return n, err
+}
@@ -0,0 +1,208 @@
+package hw05parallelexecution
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// eventRecorder is an Observer remembering all events.
+type eventRecorder struct {
+	mu             sync.Mutex
+	started        []int
+	finished       map[int]error
+	workersStarted []int
+	workersStopped []int
+	limits         []error
+}
+
+func newEventRecorder() *eventRecorder {
+	return &eventRecorder{finished: make(map[int]error)}
+}
+
+func (r *eventRecorder) TaskStarted(index int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started = append(r.started, index)
+}
+
+func (r *eventRecorder) TaskFinished(index int, _ time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.finished[index] = err
+}
+
+func (r *eventRecorder) WorkerStarted(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workersStarted = append(r.workersStarted, id)
+}
+
+func (r *eventRecorder) WorkerStopped(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workersStopped = append(r.workersStopped, id)
+}
+
+func (r *eventRecorder) LimitReached(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limits = append(r.limits, err)
+}
+
+func TestObserver(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	errTask := errors.New("failure")
+
+	t.Run("tasks and workers", func(t *testing.T) {
+		tasks := make([]ContextTask, 0, 10)
+		for i := 0; i < 10; i++ {
+			i := i
+			tasks = append(tasks, func(ctx context.Context) error {
+				if i%3 == 0 {
+					return errTask
+				}
+				return nil
+			})
+		}
+
+		observer := newEventRecorder()
+		err := RunContext(context.Background(), tasks, Options{Workers: 3, Observer: observer})
+		require.NoError(t, err)
+
+		sort.Ints(observer.started)
+		require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, observer.started)
+		require.Len(t, observer.finished, 10)
+		for i, err := range observer.finished {
+			if i%3 == 0 {
+				require.ErrorIs(t, err, errTask)
+			} else {
+				require.NoError(t, err)
+			}
+		}
+
+		sort.Ints(observer.workersStarted)
+		sort.Ints(observer.workersStopped)
+		require.Equal(t, []int{0, 1, 2}, observer.workersStarted)
+		require.Equal(t, []int{0, 1, 2}, observer.workersStopped)
+		require.Empty(t, observer.limits)
+	})
+
+	t.Run("limit reached", func(t *testing.T) {
+		tasks := []ContextTask{
+			func(ctx context.Context) error { return errTask },
+			func(ctx context.Context) error { return errTask },
+			func(ctx context.Context) error { return nil },
+		}
+
+		observer := newEventRecorder()
+		err := RunContext(context.Background(), tasks, Options{Workers: 1, MaxErrors: 2, Observer: observer})
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.Equal(t, []error{ErrErrorsLimitExceeded}, observer.limits)
+		require.Equal(t, []int{0, 1}, observer.started, "skipped tasks are not reported as started")
+	})
+
+	t.Run("cancellation is not a limit", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		tasks := []ContextTask{
+			func(ctx context.Context) error {
+				cancel()
+				return ctx.Err()
+			},
+		}
+
+		observer := newEventRecorder()
+		err := RunContext(ctx, tasks, Options{Workers: 1, MaxErrors: 1, Observer: observer})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Empty(t, observer.limits)
+	})
+
+	t.Run("graph", func(t *testing.T) {
+		g := NewGraph()
+		require.NoError(t, g.Add("a", func(ctx context.Context) error { return nil }))
+		require.NoError(t, g.Add("b", func(ctx context.Context) error { return nil }, "a"))
+
+		observer := newEventRecorder()
+		require.NoError(t, g.Run(context.Background(), Options{Workers: 2, Observer: observer}))
+		require.Equal(t, []int{0, 1}, observer.started)
+		require.Len(t, observer.workersStopped, 2)
+	})
+}
+
+func TestMetrics(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("counters and histograms", func(t *testing.T) {
+		m := NewMetrics(10*time.Millisecond, time.Millisecond)
+
+		m.WorkerStarted(0)
+		m.WorkerStarted(1)
+		m.WorkerStopped(1)
+		for i := 0; i < 4; i++ {
+			m.TaskStarted(i)
+		}
+		require.Equal(t, 4, m.InFlight())
+
+		m.TaskFinished(0, 500*time.Microsecond, nil)
+		m.TaskFinished(1, 5*time.Millisecond, nil)
+		m.TaskFinished(2, time.Second, errors.New("failure"))
+		m.LimitReached(ErrErrorsLimitExceeded)
+
+		require.Equal(t, 1, m.InFlight())
+		require.Equal(t, uint64(2), m.Completed())
+		require.Equal(t, uint64(1), m.Failed())
+
+		buf := bytes.Buffer{}
+		n, err := m.WriteTo(&buf)
+		require.NoError(t, err)
+		require.Equal(t, int64(buf.Len()), n)
+
+		lines := strings.Split(buf.String(), "\n")
+		for _, line := range []string{
+			"run_tasks_in_flight 1",
+			"run_tasks_completed_total 2",
+			"run_tasks_failed_total 1",
+			"run_workers 1",
+			"run_limits_reached_total 1",
+			"# TYPE run_task_duration_seconds histogram",
+			`run_task_duration_seconds_bucket{status="ok",le="0.001"} 1`,
+			`run_task_duration_seconds_bucket{status="ok",le="0.01"} 2`,
+			`run_task_duration_seconds_bucket{status="ok",le="+Inf"} 2`,
+			`run_task_duration_seconds_sum{status="ok"} 0.0055`,
+			`run_task_duration_seconds_count{status="ok"} 2`,
+			`run_task_duration_seconds_bucket{status="error",le="0.01"} 0`,
+			`run_task_duration_seconds_bucket{status="error",le="+Inf"} 1`,
+			`run_task_duration_seconds_sum{status="error"} 1`,
+		} {
+			require.Contains(t, lines, line)
+		}
+	})
+
+	t.Run("observes a run", func(t *testing.T) {
+		tasks := make([]ContextTask, 0, 50)
+		for i := 0; i < 50; i++ {
+			i := i
+			tasks = append(tasks, func(ctx context.Context) error {
+				if i%10 == 0 {
+					return errors.New("failure")
+				}
+				return nil
+			})
+		}
+
+		m := NewMetrics()
+		require.NoError(t, RunContext(context.Background(), tasks, Options{Workers: 5, Observer: m}))
+		require.Equal(t, 0, m.InFlight())
+		require.Equal(t, uint64(45), m.Completed())
+		require.Equal(t, uint64(5), m.Failed())
+	})
+}
@@ -0,0 +1,28 @@
+package hw05parallelexecution
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned for a task that panicked. The worker that ran it keeps working.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func newPanicError(value interface{}) *PanicError {
+	return &PanicError{Value: value, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestPanicIsolation(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("panic is converted into an error and the worker survives", func(t *testing.T) {
+		var doneCount int32
+		tasks := []ContextTask{
+			func(ctx context.Context) error { panic("boom") },
+		}
+		for i := 0; i < 5; i++ {
+			tasks = append(tasks, func(ctx context.Context) error {
+				atomic.AddInt32(&doneCount, 1)
+				return nil
+			})
+		}
+
+		err := RunContext(context.Background(), tasks, Options{Workers: 1, ReportFailures: true})
+		require.Equal(t, int32(5), doneCount)
+
+		var panicErr *PanicError
+		require.ErrorAs(t, err, &panicErr)
+		require.Equal(t, "boom", panicErr.Value)
+		require.Contains(t, string(panicErr.Stack), "panic_test.go")
+		require.EqualError(t, panicErr, "task panicked: boom")
+
+		var runErrs *RunErrors
+		require.ErrorAs(t, err, &runErrs)
+		require.Len(t, runErrs.Failed, 1)
+		require.Equal(t, 0, runErrs.Failed[0].Index)
+	})
+
+	t.Run("panics count toward the errors limit", func(t *testing.T) {
+		tasks := make([]ContextTask, 0, 10)
+		for i := 0; i < 10; i++ {
+			tasks = append(tasks, func(ctx context.Context) error { panic(io.EOF) })
+		}
+
+		err := RunContext(context.Background(), tasks, Options{Workers: 2, MaxErrors: 3})
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.ErrorIs(t, err, io.EOF, "panic value isn't unwrapped")
+	})
+
+	t.Run("Run recovers panics too", func(t *testing.T) {
+		tasks := []Task{
+			func() error { panic("boom") },
+			func() error { return nil },
+		}
+
+		require.ErrorIs(t, Run(tasks, 2, 1), ErrErrorsLimitExceeded)
+		require.NoError(t, Run(tasks, 2, 0))
+	})
+
+	t.Run("re-panic after all workers have stopped", func(t *testing.T) {
+		var doneCount int32
+		tasks := []ContextTask{
+			func(ctx context.Context) error { panic("boom") },
+		}
+		for i := 0; i < 10; i++ {
+			tasks = append(tasks, func(ctx context.Context) error {
+				atomic.AddInt32(&doneCount, 1)
+				return nil
+			})
+		}
+
+		var recovered interface{}
+		func() {
+			defer func() {
+				recovered = recover()
+			}()
+			RunContext(context.Background(), tasks, Options{Workers: 3, RePanic: true})
+		}()
+
+		var panicErr *PanicError
+		require.True(t, errors.As(recovered.(error), &panicErr))
+		require.Equal(t, "boom", panicErr.Value)
+		require.Equal(t, int32(10), doneCount, "panicked before all tasks completed")
+	})
+
+	t.Run("worker pool future gets the panic", func(t *testing.T) {
+		p, err := NewWorkerPool(PoolOptions{Workers: 1})
+		require.NoError(t, err)
+
+		f, err := p.Submit(func(ctx context.Context) error { panic("boom") })
+		require.NoError(t, err)
+
+		var panicErr *PanicError
+		require.ErrorAs(t, f.Err(), &panicErr)
+
+		f, err = p.Submit(func(ctx context.Context) error { return nil })
+		require.NoError(t, err)
+		require.NoError(t, f.Err())
+		require.NoError(t, p.Shutdown(context.Background()))
+	})
+}
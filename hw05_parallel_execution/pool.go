@@ -0,0 +1,307 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrPoolClosed = errors.New("worker pool is closed")
+	ErrQueueFull  = errors.New("worker pool queue is full")
+)
+
+// RejectPolicy tells Submit what to do when the queue of a WorkerPool is full.
+type RejectPolicy int
+
+const (
+	// Block makes Submit wait until there is room in the queue.
+	Block RejectPolicy = iota
+	// Reject makes Submit fail with ErrQueueFull.
+	Reject
+	// CallerRuns runs the task in the goroutine calling Submit.
+	CallerRuns
+)
+
+// PoolOptions configures a WorkerPool.
+type PoolOptions struct {
+	// Workers is the initial number of goroutines running tasks.
+	Workers int
+	// QueueSize is the number of tasks waiting for a free worker. Zero means tasks are never queued.
+	QueueSize int
+	// Policy is applied when the queue is full.
+	Policy RejectPolicy
+	// MaxErrors stops the pool after that many failed tasks. Zero or negative value means errors are ignored.
+	MaxErrors int
+	// TaskTimeout limits the context of every task attempt. Zero means no limit.
+	TaskTimeout time.Duration
+	// Retry reruns failed tasks. Nil means every task runs once.
+	Retry *RetryPolicy
+}
+
+// Future is the pending result of a task submitted to a WorkerPool.
+type Future struct {
+	done chan struct{}
+	err  error
+}
+
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+func (f *Future) resolve(err error) {
+	f.err = err
+	close(f.done)
+}
+
+// Done is closed once the task has finished or was dropped.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Err waits for the task and returns its error. Dropped tasks get ErrTaskNotStarted.
+func (f *Future) Err() error {
+	<-f.done
+	return f.err
+}
+
+// Wait is like Err, but gives up when ctx is done.
+func (f *Future) Wait(ctx context.Context) error {
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type poolJob struct {
+	task   ContextTask
+	future *Future
+}
+
+// WorkerPool is a long-lived set of workers executing submitted tasks.
+type WorkerPool struct {
+	opts   Options
+	policy RejectPolicy
+	size   int
+
+	ctx        context.Context // cancelled on forced shutdown or when the errors limit is hit
+	cancel     context.CancelFunc
+	errCounter *Counter
+
+	mu       sync.Mutex
+	cond     *sync.Cond // signalled on any change of queue, target, closing or err
+	queue    []poolJob
+	running  int
+	waiting  int // workers waiting for a task
+	target   int
+	closing  bool
+	err      error // set once the pool stops accepting tasks
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func NewWorkerPool(opts PoolOptions) (*WorkerPool, error) {
+	if opts.Workers <= 0 {
+		return nil, ErrNoWorkers
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &WorkerPool{
+		opts: Options{
+			Workers:     opts.Workers,
+			MaxErrors:   opts.MaxErrors,
+			TaskTimeout: opts.TaskTimeout,
+			Retry:       opts.Retry,
+		},
+		policy:     opts.Policy,
+		size:       opts.QueueSize,
+		ctx:        ctx,
+		cancel:     cancel,
+		errCounter: NewCounter(),
+		stopped:    make(chan struct{}),
+	}
+	p.cond = sync.NewCond(&p.mu)
+
+	p.mu.Lock()
+	p.resize(opts.Workers)
+	p.mu.Unlock()
+
+	return p, nil
+}
+
+// Submit enqueues task. It fails with ErrPoolClosed after Shutdown and with
+// ErrErrorsLimitExceeded once the errors limit was hit.
+func (p *WorkerPool) Submit(task ContextTask) (*Future, error) {
+	future := newFuture()
+
+	p.mu.Lock()
+	for p.err == nil && len(p.queue) >= p.size+p.idle() {
+		switch p.policy {
+		case Reject:
+			p.mu.Unlock()
+			return nil, ErrQueueFull
+		case CallerRuns:
+			p.mu.Unlock()
+			p.execute(poolJob{task: task, future: future})
+			return future, nil
+		case Block:
+			p.cond.Wait()
+		}
+	}
+	if p.err != nil {
+		err := p.err
+		p.mu.Unlock()
+		return nil, err
+	}
+
+	p.queue = append(p.queue, poolJob{task: task, future: future})
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	return future, nil
+}
+
+// Resize changes the number of workers. Extra workers stop after finishing their current task.
+func (p *WorkerPool) Resize(n int) error {
+	if n <= 0 {
+		return ErrNoWorkers
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closing {
+		return ErrPoolClosed
+	}
+	p.resize(n)
+	return nil
+}
+
+// Workers returns the number of running workers.
+func (p *WorkerPool) Workers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.running
+}
+
+// Shutdown stops accepting tasks and waits for the queued ones to complete. If ctx is done first,
+// running tasks are cancelled, queued tasks are dropped and ctx.Err() is returned without waiting
+// for the running tasks, their futures resolve once they return.
+func (p *WorkerPool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.closing = true
+	if p.err == nil {
+		p.err = ErrPoolClosed
+	}
+	p.cond.Broadcast()
+	p.checkStopped()
+	p.mu.Unlock()
+
+	select {
+	case <-p.stopped:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+	}
+
+	p.cancel()
+	p.mu.Lock()
+	p.dropQueue()
+	p.mu.Unlock()
+
+	return ctx.Err()
+}
+
+// resize must be called with p.mu held.
+func (p *WorkerPool) resize(n int) {
+	p.target = n
+	for ; p.running < p.target; p.running++ {
+		// New workers are counted as waiting right away, so Submit doesn't see them as busy.
+		p.waiting++
+		go p.work()
+	}
+	p.cond.Broadcast()
+}
+
+// idle returns the number of workers that will pick up a task right away. Must be called with p.mu held.
+func (p *WorkerPool) idle() int {
+	busy := p.running - p.waiting
+	if busy >= p.target {
+		return 0
+	}
+	return p.target - busy
+}
+
+func (p *WorkerPool) work() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		for len(p.queue) == 0 && !p.closing && p.running <= p.target {
+			p.cond.Wait()
+		}
+		p.waiting--
+
+		if p.running > p.target || len(p.queue) == 0 {
+			// Either the pool shrank or it is shutting down with nothing left to do.
+			p.running--
+			p.cond.Broadcast()
+			p.checkStopped()
+			return
+		}
+
+		j := p.queue[0]
+		p.queue[0] = poolJob{}
+		p.queue = p.queue[1:]
+		p.cond.Broadcast()
+
+		p.mu.Unlock()
+		p.execute(j)
+		p.mu.Lock()
+
+		p.waiting++
+		p.cond.Broadcast() // a blocked Submit may proceed now
+	}
+}
+
+func (p *WorkerPool) execute(j poolJob) {
+	if p.ctx.Err() != nil {
+		j.future.resolve(ErrTaskNotStarted)
+		return
+	}
+
+	err := runTask(p.ctx, j.task, p.opts)
+	if err != nil && p.opts.MaxErrors > 0 && p.errCounter.IncreaseCount() >= p.opts.MaxErrors {
+		p.mu.Lock()
+		if p.err == nil || errors.Is(p.err, ErrPoolClosed) {
+			p.err = ErrErrorsLimitExceeded
+		}
+		p.dropQueue()
+		p.mu.Unlock()
+		p.cancel()
+	}
+
+	// Resolve after the limit check, so whoever waits for the task sees the pool state it caused.
+	j.future.resolve(err)
+}
+
+// dropQueue resolves all queued tasks as not started. Must be called with p.mu held.
+func (p *WorkerPool) dropQueue() {
+	for _, j := range p.queue {
+		j.future.resolve(ErrTaskNotStarted)
+	}
+	p.queue = nil
+	p.cond.Broadcast()
+}
+
+// checkStopped must be called with p.mu held.
+func (p *WorkerPool) checkStopped() {
+	if p.closing && p.running == 0 {
+		p.stopOnce.Do(func() {
+			close(p.stopped)
+		})
+	}
+}
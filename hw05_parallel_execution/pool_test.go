@@ -0,0 +1,300 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// blockingTask returns a task that signals its start and waits for release or cancellation.
+func blockingTask(started chan<- struct{}, release <-chan struct{}) ContextTask {
+	return func(ctx context.Context) error {
+		started <- struct{}{}
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func TestWorkerPool(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("submitted tasks are executed", func(t *testing.T) {
+		p, err := NewWorkerPool(PoolOptions{Workers: 4, QueueSize: 10})
+		require.NoError(t, err)
+
+		var doneCount int32
+		futures := make([]*Future, 0, 100)
+		for i := 0; i < 100; i++ {
+			f, err := p.Submit(func(ctx context.Context) error {
+				atomic.AddInt32(&doneCount, 1)
+				return nil
+			})
+			require.NoError(t, err)
+			futures = append(futures, f)
+		}
+
+		for _, f := range futures {
+			require.NoError(t, f.Err())
+		}
+		require.Equal(t, int32(100), doneCount)
+		require.NoError(t, p.Shutdown(context.Background()))
+
+		_, err = p.Submit(func(ctx context.Context) error { return nil })
+		require.ErrorIs(t, err, ErrPoolClosed)
+	})
+
+	t.Run("future returns task error", func(t *testing.T) {
+		p, err := NewWorkerPool(PoolOptions{Workers: 1})
+		require.NoError(t, err)
+		defer p.Shutdown(context.Background())
+
+		errTask := errors.New("task error")
+		f, err := p.Submit(func(ctx context.Context) error { return errTask })
+		require.NoError(t, err)
+		require.ErrorIs(t, f.Wait(context.Background()), errTask)
+	})
+
+	t.Run("reject policy", func(t *testing.T) {
+		p, err := NewWorkerPool(PoolOptions{Workers: 1, QueueSize: 1, Policy: Reject})
+		require.NoError(t, err)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		running, err := p.Submit(blockingTask(started, release))
+		require.NoError(t, err)
+		<-started
+
+		queued, err := p.Submit(func(ctx context.Context) error { return nil })
+		require.NoError(t, err)
+
+		_, err = p.Submit(func(ctx context.Context) error { return nil })
+		require.ErrorIs(t, err, ErrQueueFull)
+
+		close(release)
+		require.NoError(t, running.Err())
+		require.NoError(t, queued.Err())
+		require.NoError(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("caller runs policy", func(t *testing.T) {
+		p, err := NewWorkerPool(PoolOptions{Workers: 1, Policy: CallerRuns})
+		require.NoError(t, err)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		_, err = p.Submit(blockingTask(started, release))
+		require.NoError(t, err)
+		<-started
+
+		var ranInline bool
+		f, err := p.Submit(func(ctx context.Context) error {
+			ranInline = true
+			return nil
+		})
+		require.NoError(t, err)
+		require.True(t, ranInline, "task wasn't run by the caller")
+		require.NoError(t, f.Err())
+
+		close(release)
+		require.NoError(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("block policy waits for room", func(t *testing.T) {
+		p, err := NewWorkerPool(PoolOptions{Workers: 1, Policy: Block})
+		require.NoError(t, err)
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		_, err = p.Submit(blockingTask(started, release))
+		require.NoError(t, err)
+		<-started
+
+		submitted := make(chan struct{})
+		go func() {
+			defer close(submitted)
+			f, err := p.Submit(func(ctx context.Context) error { return nil })
+			if err == nil {
+				err = f.Err()
+			}
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+
+		select {
+		case <-submitted:
+			t.Fatal("submit didn't block on a full pool")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		<-submitted
+		require.NoError(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("resize", func(t *testing.T) {
+		p, err := NewWorkerPool(PoolOptions{Workers: 1, QueueSize: 10})
+		require.NoError(t, err)
+
+		started := make(chan struct{}, 10)
+		release := make(chan struct{})
+		for i := 0; i < 4; i++ {
+			_, err := p.Submit(blockingTask(started, release))
+			require.NoError(t, err)
+		}
+		<-started
+
+		require.NoError(t, p.Resize(4))
+		for i := 0; i < 3; i++ {
+			<-started
+		}
+		require.Equal(t, 4, p.Workers())
+
+		require.NoError(t, p.Resize(2))
+		close(release)
+		require.Eventually(t, func() bool { return p.Workers() == 2 }, time.Second, time.Millisecond)
+
+		require.ErrorIs(t, p.Resize(0), ErrNoWorkers)
+		require.NoError(t, p.Shutdown(context.Background()))
+		require.ErrorIs(t, p.Resize(3), ErrPoolClosed)
+	})
+
+	t.Run("shutdown drains the queue", func(t *testing.T) {
+		p, err := NewWorkerPool(PoolOptions{Workers: 2, QueueSize: 50})
+		require.NoError(t, err)
+
+		var doneCount int32
+		for i := 0; i < 50; i++ {
+			_, err := p.Submit(func(ctx context.Context) error {
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&doneCount, 1)
+				return nil
+			})
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, p.Shutdown(context.Background()))
+		require.Equal(t, int32(50), doneCount)
+	})
+
+	t.Run("shutdown deadline cancels running and drops queued tasks", func(t *testing.T) {
+		p, err := NewWorkerPool(PoolOptions{Workers: 1, QueueSize: 1})
+		require.NoError(t, err)
+
+		started := make(chan struct{}, 1)
+		running, err := p.Submit(blockingTask(started, nil))
+		require.NoError(t, err)
+		<-started
+
+		queued, err := p.Submit(func(ctx context.Context) error { return nil })
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		require.ErrorIs(t, p.Shutdown(ctx), context.DeadlineExceeded)
+		require.ErrorIs(t, running.Err(), context.Canceled)
+		require.ErrorIs(t, queued.Err(), ErrTaskNotStarted)
+	})
+
+	t.Run("shutdown deadline doesn't wait for tasks ignoring cancellation", func(t *testing.T) {
+		p, err := NewWorkerPool(PoolOptions{Workers: 1})
+		require.NoError(t, err)
+
+		started, release := make(chan struct{}), make(chan struct{})
+		stubborn, err := p.Submit(func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+		require.NoError(t, err)
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		require.ErrorIs(t, p.Shutdown(ctx), context.DeadlineExceeded)
+		select {
+		case <-stubborn.Done():
+			t.Fatal("the task has been waited for")
+		default:
+		}
+
+		close(release)
+		require.NoError(t, stubborn.Err())
+	})
+
+	t.Run("errors limit stops the pool", func(t *testing.T) {
+		p, err := NewWorkerPool(PoolOptions{Workers: 1, QueueSize: 10, MaxErrors: 2})
+		require.NoError(t, err)
+
+		futures := make([]*Future, 0, 5)
+		gate := make(chan struct{})
+		for i := 0; i < 5; i++ {
+			f, err := p.Submit(func(ctx context.Context) error {
+				<-gate
+				return errors.New("failure")
+			})
+			require.NoError(t, err)
+			futures = append(futures, f)
+		}
+		close(gate)
+
+		require.Error(t, futures[0].Err())
+		require.Error(t, futures[1].Err())
+		for _, f := range futures[2:] {
+			require.ErrorIs(t, f.Err(), ErrTaskNotStarted)
+		}
+
+		_, err = p.Submit(func(ctx context.Context) error { return nil })
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.NoError(t, p.Shutdown(context.Background()))
+	})
+
+	t.Run("concurrent submitters", func(t *testing.T) {
+		p, err := NewWorkerPool(PoolOptions{Workers: 3, QueueSize: 2})
+		require.NoError(t, err)
+
+		var doneCount int32
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					f, err := p.Submit(func(ctx context.Context) error {
+						atomic.AddInt32(&doneCount, 1)
+						return nil
+					})
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					f.Err()
+				}
+			}()
+		}
+		wg.Wait()
+
+		require.NoError(t, p.Shutdown(context.Background()))
+		require.Equal(t, int32(200), doneCount)
+	})
+
+	t.Run("no workers", func(t *testing.T) {
+		_, err := NewWorkerPool(PoolOptions{})
+		require.ErrorIs(t, err, ErrNoWorkers)
+	})
+}
@@ -0,0 +1,210 @@
+package hw05parallelexecution
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrQueueClosed = errors.New("priority queue is closed")
+
+// PriorityTask is a task with a priority, tasks with higher priorities are started first.
+type PriorityTask struct {
+	Task     ContextTask
+	Priority int
+}
+
+// PriorityQueue is a queue of tasks for RunPriority. Tasks with higher priorities are taken first,
+// tasks with equal priorities are taken in the order they were pushed.
+//
+// With aging, the priority of a waiting task grows by one every aging interval, so bulk work
+// is not starved by a steady flow of urgent tasks. All waiting tasks age at the same rate, so
+// the order between two of them never changes while they wait and a plain heap is enough.
+type PriorityQueue struct {
+	aging time.Duration
+	clock Clock
+	epoch time.Time
+
+	mu      sync.Mutex
+	items   priorityHeap
+	pushed  int
+	closed  bool
+	changed chan struct{} // closed and replaced on every push and on close to wake up waiters
+}
+
+// NewPriorityQueue creates an empty queue. Aging <= 0 disables aging. Nil clock means the real one.
+func NewPriorityQueue(aging time.Duration, clock Clock) *PriorityQueue {
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	return &PriorityQueue{
+		aging:   aging,
+		clock:   clock,
+		epoch:   clock.Now(),
+		changed: make(chan struct{}),
+	}
+}
+
+// Push adds task to the queue and returns its index, the number of tasks pushed before it.
+// Task errors of RunPriority refer to that index.
+func (q *PriorityQueue) Push(task ContextTask, priority int) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return 0, ErrQueueClosed
+	}
+
+	rank := float64(priority)
+	if q.aging > 0 {
+		// A task pushed later has aged less than the tasks pushed before it.
+		rank -= float64(q.clock.Now().Sub(q.epoch)) / float64(q.aging)
+	}
+
+	index := q.pushed
+	q.pushed++
+	heap.Push(&q.items, priorityItem{job: job{index: index, task: task}, rank: rank})
+	q.notify()
+
+	return index, nil
+}
+
+// Close makes RunPriority return once the queue is empty. Push fails after Close.
+func (q *PriorityQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		q.notify()
+	}
+}
+
+// Len returns the number of waiting tasks.
+func (q *PriorityQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// pop waits for the task with the highest priority. It returns false once the queue is closed and empty
+// or ctx is done.
+func (q *PriorityQueue) pop(ctx context.Context) (job, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.items) > 0 {
+			item := heap.Pop(&q.items).(priorityItem)
+			q.mu.Unlock()
+			return item.job, true
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return job{}, false
+		}
+		changed := q.changed
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return job{}, false
+		case <-changed:
+		}
+	}
+}
+
+// drain removes all waiting tasks and returns their indices.
+func (q *PriorityQueue) drain() []int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	indices := make([]int, 0, len(q.items))
+	for _, item := range q.items {
+		indices = append(indices, item.index)
+	}
+	q.items = nil
+	return indices
+}
+
+// notify must be called with q.mu held.
+func (q *PriorityQueue) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+type priorityItem struct {
+	job
+	rank float64 // priority adjusted for aging
+}
+
+// priorityHeap implements heap.Interface, the item with the highest rank is on top.
+type priorityHeap []priorityItem
+
+func (h priorityHeap) Len() int {
+	return len(h)
+}
+
+func (h priorityHeap) Less(i, j int) bool {
+	if h[i].rank != h[j].rank {
+		return h[i].rank > h[j].rank
+	}
+	return h[i].index < h[j].index
+}
+
+func (h priorityHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *priorityHeap) Push(x interface{}) {
+	*h = append(*h, x.(priorityItem))
+}
+
+func (h *priorityHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = priorityItem{}
+	*h = old[:len(old)-1]
+	return item
+}
+
+// RunPriority is like RunContext, but takes tasks from q in priority order until q is closed and empty
+// or the run is stopped. Tasks may be pushed while the run goes on. Tasks left in q are not reported.
+// A task is taken from q only when a worker is free, so urgent tasks pushed while all workers
+// are busy run before waiting bulk tasks.
+func RunPriority(ctx context.Context, q *PriorityQueue, opts Options) error {
+	if opts.Workers <= 0 {
+		return ErrNoWorkers
+	}
+
+	r := newRunner(ctx, opts)
+	defer r.cancel()
+
+	r.runJobs(q.pop)
+
+	return r.finish()
+}
+
+// RunPriorityTasks runs tasks in priority order, tasks with equal priorities are started in slice order.
+// Task errors refer to positions in tasks.
+func RunPriorityTasks(ctx context.Context, tasks []PriorityTask, opts Options) error {
+	if opts.Workers <= 0 {
+		return ErrNoWorkers
+	}
+
+	// All tasks are pushed at once, so aging would change nothing.
+	q := NewPriorityQueue(0, nil)
+	for _, t := range tasks {
+		q.Push(t.Task, t.Priority)
+	}
+	q.Close()
+
+	r := newRunner(ctx, opts)
+	defer r.cancel()
+
+	r.runJobs(q.pop)
+	r.notStarted = append(r.notStarted, q.drain()...)
+
+	return r.finish()
+}
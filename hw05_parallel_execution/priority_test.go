@@ -0,0 +1,181 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// orderRecorder returns tasks that remember the order they were run in.
+type orderRecorder struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (r *orderRecorder) task(name string, err error) ContextTask {
+	return func(ctx context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.order = append(r.order, name)
+		return err
+	}
+}
+
+func TestPriorityQueue(t *testing.T) {
+	popAll := func(q *PriorityQueue) []int {
+		q.Close()
+		var indices []int
+		for j, ok := q.pop(context.Background()); ok; j, ok = q.pop(context.Background()) {
+			indices = append(indices, j.index)
+		}
+		return indices
+	}
+
+	t.Run("higher priority first, then push order", func(t *testing.T) {
+		q := NewPriorityQueue(0, nil)
+		for _, priority := range []int{1, 5, 1, 10, 5} {
+			_, err := q.Push(nil, priority)
+			require.NoError(t, err)
+		}
+		require.Equal(t, 5, q.Len())
+		require.Equal(t, []int{3, 1, 4, 0, 2}, popAll(q))
+	})
+
+	t.Run("aging", func(t *testing.T) {
+		clock := &manualClock{}
+		q := NewPriorityQueue(time.Second, clock)
+
+		bulk, _ := q.Push(nil, 0)
+		clock.Advance(5 * time.Second)
+		urgent, _ := q.Push(nil, 3)   // the bulk task has waited long enough to overtake it
+		critical, _ := q.Push(nil, 6) // but not this one
+
+		require.Equal(t, []int{critical, bulk, urgent}, popAll(q))
+	})
+
+	t.Run("push after close", func(t *testing.T) {
+		q := NewPriorityQueue(0, nil)
+		q.Close()
+		_, err := q.Push(nil, 0)
+		require.ErrorIs(t, err, ErrQueueClosed)
+	})
+
+	t.Run("pop waits for push", func(t *testing.T) {
+		defer goleak.VerifyNone(t)
+
+		q := NewPriorityQueue(0, nil)
+		popped := make(chan int)
+		go func() {
+			j, _ := q.pop(context.Background())
+			popped <- j.index
+		}()
+
+		q.Push(nil, 0)
+		require.Equal(t, 0, <-popped)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, ok := q.pop(ctx)
+		require.False(t, ok)
+	})
+}
+
+func TestRunPriority(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	errTask := errors.New("failure")
+
+	t.Run("tasks run in priority order", func(t *testing.T) {
+		rec := &orderRecorder{}
+		tasks := []PriorityTask{
+			{Task: rec.task("bulk-1", nil), Priority: 0},
+			{Task: rec.task("urgent", nil), Priority: 10},
+			{Task: rec.task("normal", nil), Priority: 5},
+			{Task: rec.task("bulk-2", nil), Priority: 0},
+		}
+
+		require.NoError(t, RunPriorityTasks(context.Background(), tasks, Options{Workers: 1}))
+		require.Equal(t, []string{"urgent", "normal", "bulk-1", "bulk-2"}, rec.order)
+	})
+
+	t.Run("errors limit and indices", func(t *testing.T) {
+		rec := &orderRecorder{}
+		tasks := []PriorityTask{
+			{Task: rec.task("bulk", nil), Priority: 0},
+			{Task: rec.task("failing", errTask), Priority: 10},
+			{Task: rec.task("normal", nil), Priority: 5},
+		}
+
+		err := RunPriorityTasks(context.Background(), tasks, Options{Workers: 1, MaxErrors: 1})
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.Equal(t, []string{"failing"}, rec.order)
+
+		var runErrs *RunErrors
+		require.ErrorAs(t, err, &runErrs)
+		require.Equal(t, []TaskError{{Index: 1, Err: errTask}}, runErrs.Failed)
+		require.Equal(t, []int{0, 2}, runErrs.NotStarted)
+	})
+
+	t.Run("tasks pushed during the run", func(t *testing.T) {
+		rec := &orderRecorder{}
+		q := NewPriorityQueue(0, nil)
+
+		done := make(chan error)
+		go func() {
+			done <- RunPriority(context.Background(), q, Options{Workers: 2})
+		}()
+
+		for i := 0; i < 10; i++ {
+			_, err := q.Push(rec.task("task", nil), i%3)
+			require.NoError(t, err)
+		}
+		q.Close()
+
+		require.NoError(t, <-done)
+		require.Len(t, rec.order, 10)
+		require.Equal(t, 0, q.Len())
+
+		// An urgent task pushed while the only worker is busy overtakes an older bulk one.
+		rec = &orderRecorder{}
+		q = NewPriorityQueue(0, nil)
+		go func() {
+			done <- RunPriority(context.Background(), q, Options{Workers: 1})
+		}()
+
+		started, release := make(chan struct{}), make(chan struct{})
+		_, err := q.Push(func(ctx context.Context) error {
+			close(started)
+			<-release
+			return rec.task("busy", nil)(ctx)
+		}, 0)
+		require.NoError(t, err)
+		<-started
+		_, err = q.Push(rec.task("bulk", nil), 0)
+		require.NoError(t, err)
+		_, err = q.Push(rec.task("urgent", nil), 10)
+		require.NoError(t, err)
+		close(release)
+		q.Close()
+
+		require.NoError(t, <-done)
+		require.Equal(t, []string{"busy", "urgent", "bulk"}, rec.order)
+	})
+
+	t.Run("cancellation stops waiting for tasks", func(t *testing.T) {
+		q := NewPriorityQueue(0, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.ErrorIs(t, RunPriority(ctx, q, Options{Workers: 2}), context.Canceled)
+	})
+
+	t.Run("no workers", func(t *testing.T) {
+		require.ErrorIs(t, RunPriority(context.Background(), NewPriorityQueue(0, nil), Options{}), ErrNoWorkers)
+		require.ErrorIs(t, RunPriorityTasks(context.Background(), nil, Options{}), ErrNoWorkers)
+	})
+}
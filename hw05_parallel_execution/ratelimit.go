@@ -0,0 +1,109 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket: tokens are added at Rate per second up to Burst,
+// every started task takes one. Rate and Burst can be changed while tasks are waiting.
+type RateLimiter struct {
+	clock Clock
+
+	mu      sync.Mutex
+	rate    float64
+	burst   int
+	tokens  float64
+	last    time.Time
+	changed chan struct{} // closed and replaced on every limit change to wake up waiters
+}
+
+// NewRateLimiter creates a limiter with a full bucket. Rate <= 0 means no limit. Nil clock means the real one.
+func NewRateLimiter(rate float64, burst int, clock Clock) *RateLimiter {
+	if clock == nil {
+		clock = realClock{}
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		clock:   clock,
+		rate:    rate,
+		burst:   burst,
+		tokens:  float64(burst),
+		last:    clock.Now(),
+		changed: make(chan struct{}),
+	}
+}
+
+// SetRate changes the number of tokens added per second.
+func (l *RateLimiter) SetRate(rate float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+	l.rate = rate
+	l.notify()
+}
+
+// SetBurst changes the bucket size.
+func (l *RateLimiter) SetBurst(burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+	l.burst = burst
+	l.tokens = math.Min(l.tokens, float64(burst))
+	l.notify()
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.rate <= 0 {
+			l.mu.Unlock()
+			return ctx.Err()
+		}
+
+		l.refill()
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return ctx.Err()
+		}
+
+		wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		case <-l.clock.After(wait):
+		}
+	}
+}
+
+// refill must be called with l.mu held.
+func (l *RateLimiter) refill() {
+	now := l.clock.Now()
+	if l.rate > 0 {
+		l.tokens = math.Min(float64(l.burst), l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+}
+
+// notify must be called with l.mu held.
+func (l *RateLimiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
@@ -0,0 +1,185 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// manualClock fires timers only when the test advances it.
+type manualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []manualTimer
+}
+
+type manualTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.timers = append(c.timers, manualTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.ch <- c.now
+	}
+	c.timers = pending
+}
+
+func (c *manualClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func TestRateLimiter(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("burst and refill", func(t *testing.T) {
+		clock := &manualClock{}
+		l := NewRateLimiter(10, 3, clock)
+		ctx := context.Background()
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, l.Wait(ctx))
+		}
+
+		waited := make(chan error)
+		go func() {
+			waited <- l.Wait(ctx)
+		}()
+
+		require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+		select {
+		case <-waited:
+			t.Fatal("token was taken from an empty bucket")
+		default:
+		}
+
+		clock.Advance(100 * time.Millisecond)
+		require.NoError(t, <-waited)
+	})
+
+	t.Run("tokens don't exceed burst", func(t *testing.T) {
+		clock := &manualClock{}
+		l := NewRateLimiter(10, 2, clock)
+		ctx := context.Background()
+
+		clock.Advance(time.Hour)
+		require.NoError(t, l.Wait(ctx))
+		require.NoError(t, l.Wait(ctx))
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		require.ErrorIs(t, l.Wait(cancelled), context.Canceled)
+	})
+
+	t.Run("changing rate wakes up waiters", func(t *testing.T) {
+		clock := &manualClock{}
+		l := NewRateLimiter(0.001, 1, clock)
+		ctx := context.Background()
+		require.NoError(t, l.Wait(ctx))
+
+		waited := make(chan error)
+		go func() {
+			waited <- l.Wait(ctx)
+		}()
+		require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+
+		l.SetRate(0)
+		require.NoError(t, <-waited)
+	})
+
+	t.Run("run is throttled", func(t *testing.T) {
+		clock := &manualClock{}
+		tasksCount := 5
+		tasks := make([]ContextTask, 0, tasksCount)
+
+		var startedCount int32
+		for i := 0; i < tasksCount; i++ {
+			tasks = append(tasks, func(ctx context.Context) error {
+				atomic.AddInt32(&startedCount, 1)
+				return nil
+			})
+		}
+
+		done := make(chan error)
+		go func() {
+			done <- RunContext(context.Background(), tasks, Options{
+				Workers:     3,
+				RateLimiter: NewRateLimiter(1, 1, clock),
+			})
+		}()
+
+		for i := 1; i <= tasksCount; i++ {
+			i := int32(i)
+			require.Eventually(t, func() bool { return atomic.LoadInt32(&startedCount) == i }, time.Second, time.Millisecond)
+			require.Eventually(t, func() bool { return clock.Timers() > 0 || i == int32(tasksCount) },
+				time.Second, time.Millisecond)
+			require.Equal(t, i, atomic.LoadInt32(&startedCount), "task was started without a token")
+			clock.Advance(time.Second)
+		}
+		require.NoError(t, <-done)
+	})
+
+	t.Run("waiting tasks are not started after cancellation", func(t *testing.T) {
+		clock := &manualClock{}
+		tasks := make([]ContextTask, 0, 3)
+
+		var startedCount int32
+		for i := 0; i < 3; i++ {
+			tasks = append(tasks, func(ctx context.Context) error {
+				atomic.AddInt32(&startedCount, 1)
+				return nil
+			})
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			for clock.Timers() == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+		}()
+
+		err := RunContext(ctx, tasks, Options{Workers: 1, RateLimiter: NewRateLimiter(1, 1, clock)})
+		require.ErrorIs(t, err, context.Canceled)
+
+		var runErrs *RunErrors
+		require.ErrorAs(t, err, &runErrs)
+		require.Equal(t, []int{1, 2}, runErrs.NotStarted)
+		require.Equal(t, int32(1), startedCount)
+	})
+}
@@ -0,0 +1,46 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrTaskNotStarted = errors.New("task was not started")
+
+// Result is the outcome of a single task run by RunWithResults.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// RunWithResults runs tasks like RunContext with n workers and the errors limit m,
+// and returns their outcomes in input order. Tasks that were never started get ErrTaskNotStarted,
+// tasks that panicked get *PanicError.
+// The returned error is the one RunContext would return for the same run.
+func RunWithResults[T any](
+	ctx context.Context, tasks []func(context.Context) (T, error), n, m int,
+) ([]Result[T], error) {
+	results := make([]Result[T], len(tasks))
+	ctxTasks := make([]ContextTask, len(tasks))
+
+	for i, task := range tasks {
+		i, task := i, task
+		results[i].Err = ErrTaskNotStarted
+		// Every task writes only its own slot, RunContext waits for all of them before returning.
+		ctxTasks[i] = func(ctx context.Context) (err error) {
+			results[i].Err = nil
+			defer func() {
+				if v := recover(); v != nil {
+					err = newPanicError(v)
+				}
+				results[i].Err = err
+			}()
+
+			results[i].Value, err = task(ctx)
+			return err
+		}
+	}
+
+	err := RunContext(ctx, ctxTasks, Options{Workers: n, MaxErrors: m})
+	return results, err
+}
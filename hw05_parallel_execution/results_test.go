@@ -0,0 +1,111 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestRunWithResults(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("results are in input order", func(t *testing.T) {
+		tasksCount := 50
+		tasks := make([]func(context.Context) (string, error), 0, tasksCount)
+
+		for i := 0; i < tasksCount; i++ {
+			i := i
+			tasks = append(tasks, func(ctx context.Context) (string, error) {
+				time.Sleep(time.Millisecond * time.Duration(rand.Intn(10)))
+				return strconv.Itoa(i), nil
+			})
+		}
+
+		results, err := RunWithResults(context.Background(), tasks, 10, 1)
+		require.NoError(t, err)
+		require.Len(t, results, tasksCount)
+		for i, result := range results {
+			require.NoError(t, result.Err)
+			require.Equal(t, strconv.Itoa(i), result.Value)
+		}
+	})
+
+	t.Run("errors are kept per position", func(t *testing.T) {
+		errOdd := errors.New("odd")
+		tasks := make([]func(context.Context) (int, error), 0, 10)
+
+		for i := 0; i < 10; i++ {
+			i := i
+			tasks = append(tasks, func(ctx context.Context) (int, error) {
+				if i%2 == 1 {
+					return 0, fmt.Errorf("task %d: %w", i, errOdd)
+				}
+				return i * i, nil
+			})
+		}
+
+		results, err := RunWithResults(context.Background(), tasks, 3, 0)
+		require.NoError(t, err)
+		for i, result := range results {
+			if i%2 == 1 {
+				require.ErrorIs(t, result.Err, errOdd)
+				continue
+			}
+			require.NoError(t, result.Err)
+			require.Equal(t, i*i, result.Value)
+		}
+	})
+
+	t.Run("panics are kept per position", func(t *testing.T) {
+		tasks := []func(context.Context) (int, error){
+			func(ctx context.Context) (int, error) { return 1, nil },
+			func(ctx context.Context) (int, error) { panic("boom") },
+		}
+
+		results, err := RunWithResults(context.Background(), tasks, 2, 0)
+		require.NoError(t, err)
+		require.Equal(t, Result[int]{Value: 1}, results[0])
+
+		var panicErr *PanicError
+		require.ErrorAs(t, results[1].Err, &panicErr)
+		require.Equal(t, "boom", panicErr.Value)
+		require.Zero(t, results[1].Value)
+	})
+
+	t.Run("errors limit marks not started tasks", func(t *testing.T) {
+		tasksCount := 20
+		tasks := make([]func(context.Context) (int, error), 0, tasksCount)
+
+		for i := 0; i < tasksCount; i++ {
+			tasks = append(tasks, func(ctx context.Context) (int, error) {
+				return 0, errors.New("failure")
+			})
+		}
+
+		results, err := RunWithResults(context.Background(), tasks, 2, 2)
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+
+		var runErrs *RunErrors
+		require.ErrorAs(t, err, &runErrs)
+		for _, idx := range runErrs.NotStarted {
+			require.ErrorIs(t, results[idx].Err, ErrTaskNotStarted)
+		}
+		for _, failed := range runErrs.Failed {
+			require.EqualError(t, results[failed.Index].Err, "failure")
+		}
+		require.Equal(t, tasksCount, len(runErrs.NotStarted)+len(runErrs.Failed))
+	})
+
+	t.Run("no workers", func(t *testing.T) {
+		results, err := RunWithResults[int](context.Background(), nil, 0, 1)
+		require.ErrorIs(t, err, ErrNoWorkers)
+		require.Empty(t, results)
+	})
+}
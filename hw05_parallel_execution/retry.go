@@ -0,0 +1,86 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Clock abstracts time for retries and rate limiting, so tests don't have to sleep.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// RetryPolicy makes a failed task run again after an exponential backoff with full jitter.
+// Only the error of the last attempt is reported and counted toward the errors limit.
+type RetryPolicy struct {
+	// MaxAttempts is the attempt budget of every task, including the first run.
+	MaxAttempts int
+	// BaseDelay is the upper bound of the delay before the second attempt; it doubles with every attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the upper bound of the delay. Zero means no cap.
+	MaxDelay time.Duration
+	// Retryable reports whether err is transient. Nil means every error is retried.
+	Retryable func(err error) bool
+	// Clock is used to wait between attempts. Nil means the real clock.
+	Clock Clock
+	// Random returns a number in [0, 1) used for jitter. Nil means math/rand.
+	Random func() float64
+}
+
+// run executes task until it succeeds, fails with a permanent error or exhausts the attempt budget.
+// Every attempt gets its own timeout.
+func (p *RetryPolicy) run(ctx context.Context, task ContextTask, timeout time.Duration) error {
+	clock := p.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := runAttempt(ctx, task, timeout)
+		if err == nil || attempt >= p.MaxAttempts || !p.retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-clock.After(p.backoff(attempt)):
+		}
+	}
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+// backoff returns a random delay in [0, min(MaxDelay, BaseDelay*2^(attempt-1))).
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	limit := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		if (p.MaxDelay > 0 && limit >= p.MaxDelay) || limit > limit<<1 {
+			break
+		}
+		limit <<= 1
+	}
+	if p.MaxDelay > 0 && limit > p.MaxDelay {
+		limit = p.MaxDelay
+	}
+
+	random := p.Random
+	if random == nil {
+		random = rand.Float64
+	}
+	return time.Duration(random() * float64(limit))
+}
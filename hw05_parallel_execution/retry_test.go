@@ -0,0 +1,211 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// fakeClock fires timers immediately and records the requested delays.
+// If hold is set, timers never fire.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	delays []time.Duration
+	hold   bool
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.delays = append(c.delays, d)
+	ch := make(chan time.Time, 1)
+	if !c.hold {
+		c.now = c.now.Add(d)
+		ch <- c.now
+	}
+	return ch
+}
+
+func (c *fakeClock) Delays() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.delays...)
+}
+
+var errTransient = errors.New("transient")
+
+// flakyTask fails with errTransient the given number of times before succeeding.
+func flakyTask(failures int32, attempts *int32) ContextTask {
+	return func(ctx context.Context) error {
+		if atomic.AddInt32(attempts, 1) <= failures {
+			return errTransient
+		}
+		return nil
+	}
+}
+
+func TestRetry(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("transient failures don't count toward the limit", func(t *testing.T) {
+		clock := &fakeClock{}
+		tasksCount := 10
+		tasks := make([]ContextTask, 0, tasksCount)
+		attempts := make([]int32, tasksCount)
+		for i := 0; i < tasksCount; i++ {
+			tasks = append(tasks, flakyTask(2, &attempts[i]))
+		}
+
+		err := RunContext(context.Background(), tasks, Options{
+			Workers:   3,
+			MaxErrors: 1,
+			Retry:     &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, Clock: clock},
+		})
+		require.NoError(t, err)
+		for _, count := range attempts {
+			require.Equal(t, int32(3), count)
+		}
+		require.Len(t, clock.Delays(), 2*tasksCount)
+	})
+
+	t.Run("only the final failure is counted", func(t *testing.T) {
+		var attempts int32
+		tasks := []ContextTask{flakyTask(10, &attempts)}
+
+		err := RunContext(context.Background(), tasks, Options{
+			Workers:        1,
+			MaxErrors:      2,
+			ReportFailures: true,
+			Retry:          &RetryPolicy{MaxAttempts: 4, Clock: &fakeClock{}},
+		})
+
+		var runErrs *RunErrors
+		require.ErrorAs(t, err, &runErrs)
+		require.Nil(t, runErrs.Cause)
+		require.Len(t, runErrs.Failed, 1)
+		require.ErrorIs(t, runErrs.Failed[0].Err, errTransient)
+		require.Equal(t, int32(4), attempts)
+	})
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		errPermanent := errors.New("permanent")
+		var attempts int32
+		tasks := []ContextTask{func(ctx context.Context) error {
+			atomic.AddInt32(&attempts, 1)
+			return errPermanent
+		}}
+
+		err := RunContext(context.Background(), tasks, Options{
+			Workers:   1,
+			MaxErrors: 1,
+			Retry: &RetryPolicy{
+				MaxAttempts: 5,
+				Retryable:   func(err error) bool { return errors.Is(err, errTransient) },
+				Clock:       &fakeClock{},
+			},
+		})
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.ErrorIs(t, err, errPermanent)
+		require.Equal(t, int32(1), attempts)
+	})
+
+	t.Run("exponential backoff with full jitter", func(t *testing.T) {
+		p := &RetryPolicy{
+			BaseDelay: 100 * time.Millisecond,
+			MaxDelay:  time.Second,
+			Random:    func() float64 { return 0.5 },
+		}
+
+		expected := []time.Duration{
+			50 * time.Millisecond,
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			500 * time.Millisecond, // capped by MaxDelay
+			500 * time.Millisecond,
+		}
+		for i, delay := range expected {
+			require.Equal(t, delay, p.backoff(i+1))
+		}
+
+		p.Random = func() float64 { return 0 }
+		require.Equal(t, time.Duration(0), p.backoff(3))
+
+		p.MaxDelay = 0
+		p.Random = func() float64 { return 0.999 }
+		require.Greater(t, p.backoff(100), time.Duration(0), "backoff overflowed")
+	})
+
+	t.Run("cancellation interrupts backoff", func(t *testing.T) {
+		clock := &fakeClock{hold: true}
+		var attempts int32
+		tasks := []ContextTask{flakyTask(10, &attempts)}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			for len(clock.Delays()) == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+		}()
+
+		err := RunContext(ctx, tasks, Options{
+			Workers:        1,
+			ReportFailures: true,
+			Retry:          &RetryPolicy{MaxAttempts: 10, BaseDelay: time.Hour, Clock: clock},
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorIs(t, err, errTransient)
+		require.Equal(t, int32(1), attempts)
+	})
+
+	t.Run("every attempt gets its own timeout", func(t *testing.T) {
+		var attempts int32
+		tasks := []ContextTask{func(ctx context.Context) error {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return ctx.Err()
+		}}
+
+		err := RunContext(context.Background(), tasks, Options{
+			Workers:     1,
+			MaxErrors:   1,
+			TaskTimeout: 10 * time.Millisecond,
+			Retry:       &RetryPolicy{MaxAttempts: 3, Clock: &fakeClock{}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(3), attempts)
+	})
+
+	t.Run("worker pool retries", func(t *testing.T) {
+		p, err := NewWorkerPool(PoolOptions{
+			Workers:   1,
+			MaxErrors: 1,
+			Retry:     &RetryPolicy{MaxAttempts: 2, Clock: &fakeClock{}},
+		})
+		require.NoError(t, err)
+
+		var attempts int32
+		f, err := p.Submit(flakyTask(1, &attempts))
+		require.NoError(t, err)
+		require.NoError(t, f.Err())
+		require.Equal(t, int32(2), attempts)
+		require.NoError(t, p.Shutdown(context.Background()))
+	})
+}
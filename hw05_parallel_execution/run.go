@@ -1,6 +1,7 @@
 package hw05parallelexecution
 
 import (
+	"context"
 	"errors"
 	"sync"
 )
@@ -28,53 +29,34 @@ func (c *Counter) GetCount() int {
 	return c.errCounter
 }
 
-func (c *Counter) IncreaseCount() {
+// IncreaseCount increments the counter and returns its new value.
+func (c *Counter) IncreaseCount() int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.errCounter++
+	return c.errCounter
 }
 
 // Run starts tasks in n goroutines and stops its work when receiving m errors from tasks.
+// m <= 0 means errors are ignored: all tasks are run and nil is returned.
+// Run returns nil if the limit isn't reached, use RunReport to get the failures below it.
 func Run(tasks []Task, n, m int) error {
-	if n <= 0 {
-		return ErrNoWorkers
-	}
-
-	c := NewCounter()
-	taskCh := make(chan Task)
-	wg := sync.WaitGroup{}
-
-	for i := 0; i < n; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-
-			for task := range taskCh {
-				if m > 0 && m == c.GetCount() {
-					return
-				}
+	return RunContext(context.Background(), contextTasks(tasks), Options{Workers: n, MaxErrors: m})
+}
 
-				err := task()
-				if err != nil && c.GetCount() < m {
-					c.IncreaseCount()
-				}
-			}
-		}()
-	}
+// RunReport is Run returning *RunErrors if any task failed, even when the limit isn't reached.
+func RunReport(tasks []Task, n, m int) error {
+	opts := Options{Workers: n, MaxErrors: m, ReportFailures: true}
+	return RunContext(context.Background(), contextTasks(tasks), opts)
+}
 
-	for _, task := range tasks {
-		taskCh <- task
-		if m > 0 && c.GetCount() == m {
-			break
+func contextTasks(tasks []Task) []ContextTask {
+	ctxTasks := make([]ContextTask, len(tasks))
+	for i, task := range tasks {
+		task := task
+		ctxTasks[i] = func(context.Context) error {
+			return task()
 		}
 	}
-	close(taskCh)
-
-	wg.Wait()
-
-	if m > 0 && c.GetCount() == m {
-		return ErrErrorsLimitExceeded
-	}
-
-	return nil
+	return ctxTasks
 }
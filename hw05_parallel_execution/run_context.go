@@ -0,0 +1,326 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ContextTask is a task that should stop its work once ctx is done.
+type ContextTask func(ctx context.Context) error
+
+// Options configures RunContext.
+type Options struct {
+	// Workers is the number of goroutines running tasks.
+	Workers int
+	// MaxErrors stops the run after that many failed tasks. Zero or negative value means errors are ignored.
+	MaxErrors int
+	// ErrorRatio stops the run when too many of the recent tasks failed. Nil means no ratio limit.
+	// It may be used together with MaxErrors or instead of it, e.g. for unbounded streams.
+	ErrorRatio *ErrorRatio
+	// TaskTimeout limits the context of every task attempt. Zero means no limit.
+	TaskTimeout time.Duration
+	// Retry reruns failed tasks. Nil means every task runs once.
+	Retry *RetryPolicy
+	// RateLimiter delays task starts. Nil means no rate limit.
+	RateLimiter *RateLimiter
+	// Concurrency is a weighted budget shared by running tasks. Nil means only Workers limit concurrency.
+	Concurrency *Semaphore
+	// Weight returns the share of Concurrency taken by the task at index. Nil means every task weighs 1.
+	Weight func(index int) int
+	// RePanic makes RunContext panic with the first *PanicError once all workers have stopped.
+	// Otherwise panics are returned as task errors.
+	RePanic bool
+	// ReportFailures makes RunContext return *RunErrors if any task failed, even when the run
+	// wasn't stopped. Skipped graph tasks are reported anyway.
+	ReportFailures bool
+	// Observer is notified about tasks, workers and limits of the run. Nil means no notifications.
+	Observer Observer
+}
+
+// job is a task together with its position in the input.
+type job struct {
+	index int
+	task  ContextTask
+}
+
+// runner holds the state shared by the workers of a single run.
+type runner struct {
+	ctx    context.Context // parent context
+	runCtx context.Context // cancelled on parent cancellation or when the errors limit is hit
+	cancel context.CancelFunc
+	opts   Options
+
+	errCounter *Counter
+	errWindow  *errorWindow // nil unless opts.ErrorRatio is set
+	stopOnce   sync.Once
+	stopCause  error // the limit that stopped the run, if it wasn't cancelled from outside
+
+	mu         sync.Mutex
+	failed     []TaskError
+	notStarted []int
+	skipped    []int
+	panicked   *PanicError // the first panic of the run
+}
+
+func newRunner(ctx context.Context, opts Options) *runner {
+	runCtx, cancel := context.WithCancel(ctx)
+
+	return &runner{
+		ctx:        ctx,
+		runCtx:     runCtx,
+		cancel:     cancel,
+		opts:       opts,
+		errCounter: NewCounter(),
+		errWindow:  newErrorWindow(opts.ErrorRatio),
+	}
+}
+
+// RunContext starts tasks in opts.Workers goroutines. Tasks receive a context that is cancelled
+// when ctx is done or an error limit of opts is hit; no new tasks are started after that.
+// If the run was stopped, the returned *RunErrors wraps the limit error or ctx.Err().
+func RunContext(ctx context.Context, tasks []ContextTask, opts Options) error {
+	if opts.Workers <= 0 {
+		return ErrNoWorkers
+	}
+
+	r := newRunner(ctx, opts)
+	defer r.cancel()
+
+	taken := r.run(func(context.Context) (ContextTask, bool) {
+		if len(tasks) == 0 {
+			return nil, false
+		}
+		task := tasks[0]
+		tasks = tasks[1:]
+		return task, true
+	})
+
+	for i := range tasks {
+		r.notStarted = append(r.notStarted, taken+i)
+	}
+
+	return r.finish()
+}
+
+// run starts the workers and feeds them tasks from next until it is exhausted or the run is stopped.
+// Tasks are indexed in the order they were taken from next; it returns how many were taken.
+func (r *runner) run(next func(ctx context.Context) (ContextTask, bool)) int {
+	taken := 0
+	r.runJobs(func(ctx context.Context) (job, bool) {
+		task, ok := next(ctx)
+		if !ok {
+			return job{}, false
+		}
+		taken++
+		return job{index: taken - 1, task: task}, true
+	})
+	return taken
+}
+
+// runJobs is like run, but the indices of tasks are chosen by next.
+// A task is taken from next only when a worker is free, so that tasks pushed to a PriorityQueue meanwhile
+// are ordered with the rest.
+func (r *runner) runJobs(next func(ctx context.Context) (job, bool)) {
+	ready := make(chan struct{})
+	jobCh := make(chan job)
+	stopped := make(chan struct{})
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < r.opts.Workers; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			defer r.observeWorker(id)()
+			r.work(ready, jobCh, stopped)
+		}(i)
+	}
+
+feed:
+	for r.runCtx.Err() == nil {
+		select {
+		case <-r.runCtx.Done():
+			break feed
+		case <-ready:
+		}
+
+		j, ok := next(r.runCtx)
+		if !ok {
+			break
+		}
+		// The worker that is ready waits for the job.
+		jobCh <- j
+	}
+	close(stopped)
+	close(jobCh)
+
+	wg.Wait()
+}
+
+// finish must be called after all workers have stopped.
+func (r *runner) finish() error {
+	if r.opts.RePanic && r.panicked != nil {
+		panic(r.panicked)
+	}
+	return r.result()
+}
+
+// observeWorker reports the start of worker id and returns the function reporting its stop.
+func (r *runner) observeWorker(id int) func() {
+	observer := r.opts.Observer
+	if observer == nil {
+		return func() {}
+	}
+
+	observer.WorkerStarted(id)
+	return func() {
+		observer.WorkerStopped(id)
+	}
+}
+
+// work tells the dispatcher it is ready and runs the job it gets, until stopped is closed.
+func (r *runner) work(ready chan<- struct{}, jobCh <-chan job, stopped <-chan struct{}) {
+	for {
+		select {
+		case <-stopped:
+			return
+		case ready <- struct{}{}:
+		}
+
+		j, ok := <-jobCh
+		if !ok {
+			return
+		}
+		r.execute(j)
+	}
+}
+
+// execute runs a single job unless the run was stopped. It reports whether the task was started and its error.
+func (r *runner) execute(j job) (bool, error) {
+	if r.runCtx.Err() != nil {
+		r.skip(j)
+		return false, nil
+	}
+
+	weight, err := r.acquire(j)
+	if err != nil {
+		r.skip(j)
+		return false, nil
+	}
+
+	observer := r.opts.Observer
+	if observer != nil {
+		observer.TaskStarted(j.index)
+	}
+	start := time.Now()
+
+	err = runTask(r.runCtx, j.task, r.opts)
+	if observer != nil {
+		observer.TaskFinished(j.index, time.Since(start), err)
+	}
+	if err != nil {
+		r.fail(j, err)
+	}
+	if r.errWindow != nil && r.errWindow.record(err != nil) {
+		r.stop(ErrErrorRatioExceeded)
+	}
+
+	if r.opts.Concurrency != nil {
+		r.opts.Concurrency.Release(weight)
+	}
+
+	return true, err
+}
+
+// acquire waits for the rate limiter and the concurrency budget before a task is started.
+func (r *runner) acquire(j job) (int, error) {
+	if r.opts.RateLimiter != nil {
+		if err := r.opts.RateLimiter.Wait(r.runCtx); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.opts.Concurrency == nil {
+		return 0, nil
+	}
+
+	weight := 1
+	if r.opts.Weight != nil {
+		weight = r.opts.Weight(j.index)
+	}
+	return r.opts.Concurrency.Acquire(r.runCtx, weight)
+}
+
+func (r *runner) skip(j job) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notStarted = append(r.notStarted, j.index)
+}
+
+func (r *runner) fail(j job, err error) {
+	r.mu.Lock()
+	r.failed = append(r.failed, TaskError{Index: j.index, Err: err})
+	var panicErr *PanicError
+	if r.panicked == nil && errors.As(err, &panicErr) {
+		r.panicked = panicErr
+	}
+	r.mu.Unlock()
+
+	if r.opts.MaxErrors > 0 && r.errCounter.IncreaseCount() >= r.opts.MaxErrors {
+		r.stop(ErrErrorsLimitExceeded)
+	}
+}
+
+// stop cancels the run because of cause.
+func (r *runner) stop(cause error) {
+	r.stopOnce.Do(func() {
+		// The limit only counts as the reason if the run wasn't already cancelled from outside.
+		if r.ctx.Err() == nil {
+			r.stopCause = cause
+		}
+		r.cancel()
+
+		if r.stopCause != nil && r.opts.Observer != nil {
+			r.opts.Observer.LimitReached(r.stopCause)
+		}
+	})
+}
+
+// result must be called after all workers have stopped.
+func (r *runner) result() error {
+	cause := r.stopCause
+	if cause == nil {
+		cause = r.ctx.Err()
+	}
+
+	// Skipped tasks are always reported, nothing else would tell they haven't run.
+	if cause == nil && len(r.skipped) == 0 && (!r.opts.ReportFailures || len(r.failed) == 0) {
+		return nil
+	}
+
+	return newRunErrors(cause, r.failed, r.notStarted, r.skipped)
+}
+
+func runTask(ctx context.Context, task ContextTask, opts Options) error {
+	if opts.Retry != nil {
+		return opts.Retry.run(ctx, task, opts.TaskTimeout)
+	}
+	return runAttempt(ctx, task, opts.TaskTimeout)
+}
+
+func runAttempt(ctx context.Context, task ContextTask, timeout time.Duration) (err error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			err = newPanicError(v)
+		}
+	}()
+
+	return task(ctx)
+}
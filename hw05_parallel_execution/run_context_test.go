@@ -0,0 +1,129 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestRunContext(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("cancellation from outside", func(t *testing.T) {
+		tasksCount := 100
+		tasks := make([]ContextTask, 0, tasksCount)
+
+		var startedCount int32
+		for i := 0; i < tasksCount; i++ {
+			tasks = append(tasks, func(ctx context.Context) error {
+				atomic.AddInt32(&startedCount, 1)
+				<-ctx.Done()
+				return ctx.Err()
+			})
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			for atomic.LoadInt32(&startedCount) < 5 {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+		}()
+
+		err := RunContext(ctx, tasks, Options{Workers: 5, MaxErrors: 10})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, int32(5), atomic.LoadInt32(&startedCount), "tasks were started after cancellation")
+	})
+
+	t.Run("already cancelled context", func(t *testing.T) {
+		var startedCount int32
+		tasks := []ContextTask{func(ctx context.Context) error {
+			atomic.AddInt32(&startedCount, 1)
+			return nil
+		}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := RunContext(ctx, tasks, Options{Workers: 1})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, int32(0), startedCount)
+	})
+
+	t.Run("errors limit cancels running tasks", func(t *testing.T) {
+		workersCount := 4
+		tasks := make([]ContextTask, 0, workersCount*10)
+
+		var startedCount, cancelledCount int32
+		// Every worker takes a task that waits for cancellation, then the failing one triggers it.
+		for i := 0; i < workersCount-1; i++ {
+			tasks = append(tasks, func(ctx context.Context) error {
+				atomic.AddInt32(&startedCount, 1)
+				<-ctx.Done()
+				atomic.AddInt32(&cancelledCount, 1)
+				return nil
+			})
+		}
+		tasks = append(tasks, func(ctx context.Context) error {
+			for atomic.LoadInt32(&startedCount) < int32(workersCount-1) {
+				time.Sleep(time.Millisecond)
+			}
+			return errors.New("failure")
+		})
+		for i := 0; i < workersCount*9; i++ {
+			tasks = append(tasks, func(ctx context.Context) error {
+				t.Error("task was started after the limit was hit")
+				return nil
+			})
+		}
+
+		err := RunContext(context.Background(), tasks, Options{Workers: workersCount, MaxErrors: 1})
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.Equal(t, int32(workersCount-1), cancelledCount)
+	})
+
+	t.Run("per task timeout", func(t *testing.T) {
+		tasksCount := 10
+		tasks := make([]ContextTask, 0, tasksCount)
+
+		var timedOutCount int32
+		for i := 0; i < tasksCount; i++ {
+			tasks = append(tasks, func(ctx context.Context) error {
+				<-ctx.Done()
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					atomic.AddInt32(&timedOutCount, 1)
+				}
+				return nil
+			})
+		}
+
+		err := RunContext(context.Background(), tasks, Options{Workers: 5, TaskTimeout: 10 * time.Millisecond})
+		require.NoError(t, err)
+		require.Equal(t, int32(tasksCount), timedOutCount)
+	})
+
+	t.Run("timed out tasks count as errors", func(t *testing.T) {
+		tasksCount := 10
+		tasks := make([]ContextTask, 0, tasksCount)
+
+		for i := 0; i < tasksCount; i++ {
+			tasks = append(tasks, func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+		}
+
+		err := RunContext(context.Background(), tasks, Options{Workers: 2, MaxErrors: 3, TaskTimeout: time.Millisecond})
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+	})
+
+	t.Run("no workers", func(t *testing.T) {
+		err := RunContext(context.Background(), nil, Options{})
+		require.ErrorIs(t, err, ErrNoWorkers)
+	})
+}
@@ -0,0 +1,109 @@
+package hw05parallelexecution
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// Semaphore is a weighted concurrency budget. Heavy tasks take more of it than light ones.
+// Waiters are served in FIFO order, so heavy tasks are not starved by a stream of light ones.
+type Semaphore struct {
+	mu       sync.Mutex
+	capacity int
+	used     int
+	waiters  list.List // of *semaphoreWaiter
+}
+
+type semaphoreWaiter struct {
+	weight int
+	ready  chan struct{}
+}
+
+func NewSemaphore(capacity int) *Semaphore {
+	return &Semaphore{capacity: capacity}
+}
+
+// SetCapacity changes the budget. Running tasks are not interrupted, new ones wait until
+// enough of the budget is released. Zero capacity pauses starting new tasks.
+func (s *Semaphore) SetCapacity(capacity int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.capacity = capacity
+	s.wakeWaiters()
+}
+
+// Acquire takes weight units of the budget, blocking until they are available or ctx is done.
+// A weight above the capacity is clamped, so such a task runs alone instead of blocking forever.
+func (s *Semaphore) Acquire(ctx context.Context, weight int) (int, error) {
+	s.mu.Lock()
+	weight = s.clamp(weight)
+	if s.waiters.Len() == 0 && s.used+weight <= s.capacity {
+		s.used += weight
+		s.mu.Unlock()
+		return weight, nil
+	}
+
+	w := &semaphoreWaiter{weight: weight, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return w.weight, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		select {
+		case <-w.ready:
+			// Acquired concurrently with cancellation, give it back.
+			s.used -= w.weight
+		default:
+			s.waiters.Remove(elem)
+		}
+		s.wakeWaiters()
+		return 0, ctx.Err()
+	}
+}
+
+// Release returns weight units previously taken by Acquire.
+func (s *Semaphore) Release(weight int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.used -= weight
+	s.wakeWaiters()
+}
+
+// clamp must be called with s.mu held.
+func (s *Semaphore) clamp(weight int) int {
+	if weight < 1 {
+		weight = 1
+	}
+	if s.capacity > 0 && weight > s.capacity {
+		weight = s.capacity
+	}
+	return weight
+}
+
+// wakeWaiters must be called with s.mu held.
+func (s *Semaphore) wakeWaiters() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+
+		w := front.Value.(*semaphoreWaiter)
+		w.weight = s.clamp(w.weight)
+		if s.used+w.weight > s.capacity {
+			return
+		}
+
+		s.used += w.weight
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
@@ -0,0 +1,143 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestSemaphore(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	ctx := context.Background()
+
+	t.Run("heavy waiter is not overtaken by light ones", func(t *testing.T) {
+		s := NewSemaphore(4)
+
+		taken, err := s.Acquire(ctx, 4)
+		require.NoError(t, err)
+		require.Equal(t, 4, taken)
+
+		heavy := make(chan struct{})
+		go func() {
+			s.Acquire(ctx, 3)
+			close(heavy)
+		}()
+		require.Eventually(t, func() bool { return waiters(s) == 1 }, time.Second, time.Millisecond)
+
+		light := make(chan struct{})
+		go func() {
+			s.Acquire(ctx, 1)
+			close(light)
+		}()
+		require.Eventually(t, func() bool { return waiters(s) == 2 }, time.Second, time.Millisecond)
+
+		s.Release(1)
+		select {
+		case <-light:
+			t.Fatal("light task overtook the heavy one")
+		case <-heavy:
+			t.Fatal("heavy task got more than the capacity")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		s.Release(3)
+		<-heavy
+		<-light
+	})
+
+	t.Run("weight is clamped to capacity", func(t *testing.T) {
+		s := NewSemaphore(2)
+
+		taken, err := s.Acquire(ctx, 10)
+		require.NoError(t, err)
+		require.Equal(t, 2, taken)
+		s.Release(taken)
+	})
+
+	t.Run("capacity can be changed", func(t *testing.T) {
+		s := NewSemaphore(1)
+		_, err := s.Acquire(ctx, 1)
+		require.NoError(t, err)
+
+		acquired := make(chan struct{})
+		go func() {
+			s.Acquire(ctx, 1)
+			close(acquired)
+		}()
+		require.Eventually(t, func() bool { return waiters(s) == 1 }, time.Second, time.Millisecond)
+
+		s.SetCapacity(2)
+		<-acquired
+	})
+
+	t.Run("cancelled waiter leaves the queue", func(t *testing.T) {
+		s := NewSemaphore(1)
+		_, err := s.Acquire(ctx, 1)
+		require.NoError(t, err)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		result := make(chan error)
+		go func() {
+			_, err := s.Acquire(cancelled, 1)
+			result <- err
+		}()
+		require.Eventually(t, func() bool { return waiters(s) == 1 }, time.Second, time.Millisecond)
+
+		cancel()
+		require.ErrorIs(t, <-result, context.Canceled)
+		require.Equal(t, 0, waiters(s))
+
+		s.Release(1)
+		_, err = s.Acquire(ctx, 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("weighted tasks share the budget", func(t *testing.T) {
+		tasksCount := 40
+		capacity := 5
+
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		weight := func(index int) int { return index%3 + 1 }
+
+		tasks := make([]ContextTask, 0, tasksCount)
+		for i := 0; i < tasksCount; i++ {
+			w := weight(i)
+			tasks = append(tasks, func(ctx context.Context) error {
+				mu.Lock()
+				inFlight += w
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				mu.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				inFlight -= w
+				mu.Unlock()
+				return nil
+			})
+		}
+
+		err := RunContext(ctx, tasks, Options{
+			Workers:     10,
+			Concurrency: NewSemaphore(capacity),
+			Weight:      weight,
+		})
+		require.NoError(t, err)
+		require.LessOrEqual(t, maxInFlight, capacity)
+		require.Greater(t, maxInFlight, 1, "tasks were run sequentially?")
+	})
+}
+
+func waiters(s *Semaphore) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waiters.Len()
+}
@@ -0,0 +1,100 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrErrorRatioExceeded = errors.New("error ratio exceeded")
+
+// ErrorRatio is a limit on the share of failed tasks among the last Window completed ones.
+type ErrorRatio struct {
+	// Window is the number of the most recently completed tasks taken into account.
+	Window int
+	// Threshold is the maximum allowed share of failures in the window, e.g. 0.05 for 5%.
+	Threshold float64
+}
+
+// errorWindow is a ring buffer of the outcomes of the last completed tasks.
+type errorWindow struct {
+	mu        sync.Mutex
+	threshold float64
+	outcomes  []bool // true for a failure
+	pos       int
+	filled    bool
+	failures  int
+}
+
+func newErrorWindow(ratio *ErrorRatio) *errorWindow {
+	if ratio == nil || ratio.Window <= 0 {
+		return nil
+	}
+
+	return &errorWindow{
+		threshold: ratio.Threshold,
+		outcomes:  make([]bool, ratio.Window),
+	}
+}
+
+// record adds the outcome of a task and reports whether the ratio is exceeded.
+// The ratio is checked only once the window is full, so a few early failures don't stop the run.
+func (w *errorWindow) record(failed bool) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.outcomes[w.pos] {
+		w.failures--
+	}
+	if failed {
+		w.failures++
+	}
+	w.outcomes[w.pos] = failed
+
+	w.pos++
+	if w.pos == len(w.outcomes) {
+		w.pos = 0
+		w.filled = true
+	}
+
+	return w.filled && float64(w.failures) > w.threshold*float64(len(w.outcomes))
+}
+
+// RunStream is like RunContext, but takes tasks from a channel until it is closed or the run is stopped.
+// Tasks are indexed in the order they were received. Tasks left in the channel are not reported.
+func RunStream(ctx context.Context, tasks <-chan ContextTask, opts Options) error {
+	if opts.Workers <= 0 {
+		return ErrNoWorkers
+	}
+
+	r := newRunner(ctx, opts)
+	defer r.cancel()
+
+	r.run(func(ctx context.Context) (ContextTask, bool) {
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case task, ok := <-tasks:
+			return task, ok
+		}
+	})
+
+	return r.finish()
+}
+
+// RunIter is like RunStream, but pulls tasks from next until it returns false.
+// next is called from a single goroutine and is not called after the run is stopped.
+func RunIter(ctx context.Context, next func() (ContextTask, bool), opts Options) error {
+	if opts.Workers <= 0 {
+		return ErrNoWorkers
+	}
+
+	r := newRunner(ctx, opts)
+	defer r.cancel()
+
+	r.run(func(context.Context) (ContextTask, bool) {
+		return next()
+	})
+
+	return r.finish()
+}
@@ -0,0 +1,151 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestRunStream(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("tasks are taken until the channel is closed", func(t *testing.T) {
+		tasks := make(chan ContextTask)
+		var doneCount int32
+
+		go func() {
+			defer close(tasks)
+			for i := 0; i < 100; i++ {
+				tasks <- func(ctx context.Context) error {
+					atomic.AddInt32(&doneCount, 1)
+					return nil
+				}
+			}
+		}()
+
+		require.NoError(t, RunStream(context.Background(), tasks, Options{Workers: 4, MaxErrors: 1}))
+		require.Equal(t, int32(100), doneCount)
+	})
+
+	t.Run("cancellation stops an endless stream", func(t *testing.T) {
+		tasks := make(chan ContextTask)
+		ctx, cancel := context.WithCancel(context.Background())
+		var doneCount int32
+
+		// Nobody closes the channel: RunStream must return on its own.
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case tasks <- func(ctx context.Context) error {
+					if atomic.AddInt32(&doneCount, 1) == 50 {
+						cancel()
+					}
+					return nil
+				}:
+				}
+			}
+		}()
+
+		err := RunStream(ctx, tasks, Options{Workers: 4})
+		require.ErrorIs(t, err, context.Canceled)
+		require.GreaterOrEqual(t, atomic.LoadInt32(&doneCount), int32(50))
+	})
+
+	t.Run("iterator source", func(t *testing.T) {
+		i := 0
+		var doneCount int32
+		next := func() (ContextTask, bool) {
+			if i == 30 {
+				return nil, false
+			}
+			i++
+			return func(ctx context.Context) error {
+				atomic.AddInt32(&doneCount, 1)
+				return nil
+			}, true
+		}
+
+		require.NoError(t, RunIter(context.Background(), next, Options{Workers: 3}))
+		require.Equal(t, int32(30), doneCount)
+	})
+
+	t.Run("no workers", func(t *testing.T) {
+		require.ErrorIs(t, RunStream(context.Background(), nil, Options{}), ErrNoWorkers)
+		require.ErrorIs(t, RunIter(context.Background(), nil, Options{}), ErrNoWorkers)
+	})
+}
+
+func TestErrorRatio(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	errTask := errors.New("failure")
+
+	// tasksWithFailures returns an endless iterator whose every period-th task fails.
+	tasksWithFailures := func(period int, taken *int) func() (ContextTask, bool) {
+		return func() (ContextTask, bool) {
+			*taken++
+			failed := *taken%period == 0
+			return func(ctx context.Context) error {
+				if failed {
+					return errTask
+				}
+				return nil
+			}, true
+		}
+	}
+
+	t.Run("run stops when the ratio is exceeded", func(t *testing.T) {
+		taken := 0
+		// Every 10th task fails, that is 10% > 5%.
+		err := RunIter(context.Background(), tasksWithFailures(10, &taken), Options{
+			Workers:    1,
+			ErrorRatio: &ErrorRatio{Window: 1000, Threshold: 0.05},
+		})
+		require.ErrorIs(t, err, ErrErrorRatioExceeded)
+		require.ErrorIs(t, err, errTask)
+		require.False(t, errors.Is(err, ErrErrorsLimitExceeded))
+		// The window has to be full first.
+		require.GreaterOrEqual(t, taken, 1000)
+		require.Less(t, taken, 1010)
+	})
+
+	t.Run("ratio below the threshold doesn't stop the run", func(t *testing.T) {
+		taken := 0
+		next := tasksWithFailures(50, &taken) // 2% of failures
+		limited := func() (ContextTask, bool) {
+			if taken == 5000 {
+				return nil, false
+			}
+			return next()
+		}
+
+		err := RunIter(context.Background(), limited, Options{
+			Workers:    4,
+			ErrorRatio: &ErrorRatio{Window: 1000, Threshold: 0.05},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 5000, taken)
+	})
+
+	t.Run("window slides", func(t *testing.T) {
+		w := newErrorWindow(&ErrorRatio{Window: 4, Threshold: 0.5})
+
+		require.False(t, w.record(true))
+		require.False(t, w.record(true))
+		require.False(t, w.record(true), "window isn't full yet")
+		require.True(t, w.record(false))  // 3 of 4
+		require.False(t, w.record(false)) // the oldest failure is out, 2 of 4 is not above 0.5
+		require.False(t, w.record(false)) // 1 of 4
+		require.False(t, w.record(true))  // 1 of 4, a failure replaced a failure
+		require.False(t, w.record(true))  // 2 of 4
+		require.True(t, w.record(true))   // 3 of 4
+
+		require.Nil(t, newErrorWindow(nil))
+	})
+}
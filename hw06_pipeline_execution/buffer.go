@@ -0,0 +1,157 @@
+package hw06pipelineexecution
+
+// OverflowPolicy tells a buffer between stages what to do with a value when it is full.
+type OverflowPolicy int
+
+const (
+	// Block makes the stage wait until the next one takes a value.
+	Block OverflowPolicy = iota
+	// DropNewest drops the value.
+	DropNewest
+	// DropOldest drops the oldest buffered value to make room for the new one.
+	DropOldest
+	// Sample keeps one of every Buffer.Every values arriving while the buffer is full in place
+	// of the oldest buffered value, the others are dropped.
+	Sample
+)
+
+// Buffer configures the queue between a stage and the next one.
+type Buffer struct {
+	// Size is the number of values the queue holds, 0 means an unbuffered handoff.
+	Size   int
+	Policy OverflowPolicy
+	// Every is the sampling rate of the Sample policy, values below 1 mean 1.
+	Every int
+}
+
+// WithBuffer returns the pipeline with a queue of the given configuration after its last stage,
+// or after its input if it has no stages yet. Values dropped by the queue are counted
+// in StageStats.Dropped and Execution.Dropped.
+func (p Pipeline[A, B]) WithBuffer(b Buffer) Pipeline[A, B] {
+	buffers := make(map[int]Buffer, len(p.buffers)+1)
+	for stage, b := range p.buffers {
+		buffers[stage] = b
+	}
+	buffers[p.stages-1] = b
+
+	p.buffers = buffers
+	return p
+}
+
+// Dropped returns the number of values dropped by the buffers of the pipeline so far.
+func (e *Execution[B]) Dropped() uint64 {
+	e.x.mu.Lock()
+	defer e.x.mu.Unlock()
+	return e.x.dropped
+}
+
+func (x *execution) drop(stage int) {
+	x.mu.Lock()
+	x.dropped++
+	x.mu.Unlock()
+
+	if stage >= 0 {
+		x.metrics.drop(stage)
+	}
+}
+
+// ring is a fixed-size FIFO queue.
+type ring[T any] struct {
+	values []T
+	head   int
+	n      int
+}
+
+func (r *ring[T]) full() bool {
+	return r.n == len(r.values)
+}
+
+func (r *ring[T]) push(v T) {
+	r.values[(r.head+r.n)%len(r.values)] = v
+	r.n++
+}
+
+func (r *ring[T]) pop() T {
+	var zero T
+	v := r.values[r.head]
+	r.values[r.head] = zero
+	r.head = (r.head + 1) % len(r.values)
+	r.n--
+	return v
+}
+
+// bufferProcess is stageProcess with a queue of the values the next stage hasn't taken yet.
+func bufferProcess[T any](x *execution, stage int, b Buffer, in <-chan T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+
+		p := x.probe(stage)
+		queue := ring[T]{values: make([]T, b.Size)}
+		every := b.Every
+		if every < 1 {
+			every = 1
+		}
+		overflowed := 0 // values arrived since the queue has got full, for Sample
+		var draining In // stops reading the input of the pipeline in the Drain mode, the queue is sent still
+		if stage < 0 && x.draining != x.done {
+			draining = x.draining
+		}
+
+		for in != nil || queue.n > 0 {
+			// Receiving and sending are disabled by nil channels.
+			recv, send := in, out
+			var next T
+			if queue.n == 0 {
+				send = nil
+			} else {
+				next = queue.values[queue.head]
+			}
+			full := queue.full()
+			if full && b.Policy == Block {
+				recv = nil
+			}
+
+			waitFrom := p.now()
+			select {
+			case <-x.done:
+				return
+			case <-draining:
+				in, draining = nil, nil
+			case v, ok := <-recv:
+				if !ok {
+					in = nil
+					continue
+				}
+				if queue.n > 0 {
+					// The next stage hasn't been waiting for the value.
+					waitFrom = p.now()
+				}
+				p.received(v, waitFrom)
+
+				switch {
+				case !full:
+					overflowed = 0
+					queue.push(v)
+				case b.Policy == DropNewest, b.Policy == Sample && overflowed%every != 0:
+					overflowed++
+					x.drop(stage)
+				default: // DropOldest or a sampled value
+					overflowed++
+					queue.pop()
+					queue.push(v)
+					x.drop(stage)
+				}
+			case send <- next:
+				if !full || b.Policy != Block {
+					// The stage hasn't been blocked by the next one.
+					waitFrom = p.now()
+				}
+				p.sent(next, waitFrom)
+				queue.pop()
+			}
+		}
+	}()
+
+	return out
+}
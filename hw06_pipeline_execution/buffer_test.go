@@ -0,0 +1,146 @@
+package hw06pipelineexecution
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// gate makes a stage that doesn't take values until release is closed.
+func gate(release <-chan struct{}) TypedStage[int, int] {
+	return func(in <-chan int) <-chan int {
+		out := make(chan int)
+		go func() {
+			defer close(out)
+			<-release
+			for v := range in {
+				out <- v
+			}
+		}()
+		return out
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	t.Run("block", func(t *testing.T) {
+		release := make(chan struct{})
+		p := Then(NewPipeline[int]().WithBuffer(Buffer{Size: 2}), gate(release))
+
+		in := make(chan int)
+		e := p.ExecuteErr(in, nil, Abort)
+		in <- 1
+		in <- 2
+		select {
+		case in <- 3:
+			require.Fail(t, "the buffer is full")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		close(release)
+		in <- 3
+		close(in)
+
+		result := make([]int, 0, 3)
+		for v := range e.Out {
+			result = append(result, v)
+		}
+		require.NoError(t, e.Wait())
+		require.Equal(t, []int{1, 2, 3}, result)
+		require.Zero(t, e.Dropped())
+	})
+
+	tests := []struct {
+		name     string
+		buffer   Buffer
+		expected []int
+		dropped  uint64
+	}{
+		{name: "drop newest", buffer: Buffer{Size: 2, Policy: DropNewest}, expected: []int{1, 2}, dropped: 4},
+		{name: "drop oldest", buffer: Buffer{Size: 2, Policy: DropOldest}, expected: []int{5, 6}, dropped: 4},
+		{name: "sample", buffer: Buffer{Size: 2, Policy: Sample, Every: 2}, expected: []int{3, 5}, dropped: 4},
+		{name: "sample every value", buffer: Buffer{Size: 2, Policy: Sample}, expected: []int{5, 6}, dropped: 4},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			release := make(chan struct{})
+			p := Then(NewPipeline[int]().WithBuffer(tc.buffer), gate(release))
+
+			in := make(chan int)
+			e := p.ExecuteErr(in, nil, Abort)
+			// The buffer never blocks, all values are taken before the next stage takes any.
+			for i := 1; i <= 6; i++ {
+				in <- i
+			}
+			close(release)
+			close(in)
+
+			result := make([]int, 0, 2)
+			for v := range e.Out {
+				result = append(result, v)
+			}
+			require.NoError(t, e.Wait())
+			require.Equal(t, tc.expected, result)
+			require.Equal(t, tc.dropped, e.Dropped())
+		})
+	}
+
+	t.Run("dropped by stage", func(t *testing.T) {
+		m := NewMetrics()
+		release := make(chan struct{})
+		p := Then(NewPipeline[int](), typedStage(func(v int) int { return v }))
+		p = Then(p.WithBuffer(Buffer{Size: 1, Policy: DropNewest}), gate(release)).WithMetrics(m)
+
+		in := make(chan int)
+		e := p.ExecuteErr(in, nil, Abort)
+		for i := 1; i <= 3; i++ {
+			in <- i
+		}
+		// The last value may still be in the first stage.
+		require.Eventually(t, func() bool { return m.Snapshot()[0].Emitted == 3 }, time.Second, time.Millisecond)
+		close(release)
+		close(in)
+
+		for range e.Out {
+		}
+		require.NoError(t, e.Wait())
+
+		stats := m.Snapshot()
+		require.Equal(t, uint64(2), stats[0].Dropped)
+		require.Zero(t, stats[1].Dropped)
+		require.Equal(t, uint64(2), e.Dropped())
+	})
+
+	t.Run("the buffer is emptied when the input is closed", func(t *testing.T) {
+		p := NewPipeline[int]().WithBuffer(Buffer{Size: 10})
+
+		in := make(chan int, 5)
+		for i := 1; i <= 5; i++ {
+			in <- i
+		}
+		close(in)
+
+		result := make([]int, 0, 5)
+		for v := range p.Execute(in, nil) {
+			result = append(result, v)
+		}
+		require.Equal(t, []int{1, 2, 3, 4, 5}, result)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		done := make(Bi)
+		p := NewPipeline[int]().WithBuffer(Buffer{Size: 10, Policy: DropOldest})
+
+		in := make(chan int)
+		out := p.Execute(in, done)
+		in <- 1
+		close(done)
+		for range out {
+		}
+	})
+}
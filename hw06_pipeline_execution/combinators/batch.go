@@ -0,0 +1,53 @@
+package combinators
+
+import (
+	"context"
+	"time"
+
+	hw06pipelineexecution "github.com/a-klimenko/go-otus-hw/hw06_pipeline_execution"
+)
+
+// Batch groups values into slices of up to size values. A batch is also emitted once window has passed
+// since its first value. Size <= 0 means no limit on the count, window <= 0 means no limit on the time.
+// The last incomplete batch is emitted when the input is closed. Nil clock means the real one.
+func Batch[T any](size int, window time.Duration, clock Clock) hw06pipelineexecution.TypedContextStage[T, []T] {
+	clock = clockOrReal(clock)
+
+	return stage(func(ctx context.Context, in <-chan T, out chan<- []T) {
+		var batch []T
+		var timeout <-chan time.Time // nil while the batch is empty
+
+		flush := func() bool {
+			b := batch
+			batch = nil
+			timeout = nil
+			return send(ctx, out, b)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timeout:
+				if !flush() {
+					return
+				}
+			case v, ok := <-in:
+				if !ok {
+					if len(batch) > 0 {
+						flush()
+					}
+					return
+				}
+
+				batch = append(batch, v)
+				if len(batch) == 1 && window > 0 {
+					timeout = clock.After(window)
+				}
+				if len(batch) == size && !flush() {
+					return
+				}
+			}
+		}
+	})
+}
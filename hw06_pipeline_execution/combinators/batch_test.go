@@ -0,0 +1,72 @@
+package combinators
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestBatch(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("by count", func(t *testing.T) {
+		require.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, apply(Batch[int](2, 0, nil), 1, 2, 3, 4, 5))
+		require.Nil(t, apply(Batch[int](2, 0, nil)))
+	})
+
+	t.Run("by time window", func(t *testing.T) {
+		clock := &manualClock{}
+		in := make(chan int)
+		out := Batch[int](10, time.Second, clock)(context.Background(), in)
+
+		in <- 1
+		in <- 2
+		require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+
+		clock.Advance(999 * time.Millisecond)
+		in <- 3
+		clock.Advance(time.Millisecond)
+		require.Equal(t, []int{1, 2, 3}, <-out)
+
+		// The window of the next batch starts with its first value.
+		in <- 4
+		require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+		clock.Advance(time.Second)
+		require.Equal(t, []int{4}, <-out)
+
+		close(in)
+		_, ok := <-out
+		require.False(t, ok)
+	})
+
+	t.Run("count and time window", func(t *testing.T) {
+		clock := &manualClock{}
+		in := make(chan int)
+		out := Batch[int](2, time.Second, clock)(context.Background(), in)
+
+		go func() {
+			in <- 1
+			in <- 2
+			in <- 3
+		}()
+		require.Equal(t, []int{1, 2}, <-out)
+
+		require.Eventually(t, func() bool { return clock.Timers() == 2 }, time.Second, time.Millisecond)
+		clock.Advance(time.Second)
+		require.Equal(t, []int{3}, <-out)
+
+		close(in)
+		require.Nil(t, collect(out))
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		out := Batch[int](3, 0, nil)(ctx, counter(ctx))
+		require.Equal(t, []int{0, 1, 2}, <-out)
+		cancel()
+		collect(out)
+	})
+}
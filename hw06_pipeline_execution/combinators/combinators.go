@@ -0,0 +1,130 @@
+// Package combinators contains basic stages for typed pipelines, they are added with
+// hw06pipelineexecution.ThenContext. Stages stop once their context is done,
+// functions fanning channels in or out take the context explicitly.
+package combinators
+
+import (
+	"context"
+
+	hw06pipelineexecution "github.com/a-klimenko/go-otus-hw/hw06_pipeline_execution"
+)
+
+// send sends v to out unless ctx is done first. It reports whether v was sent.
+func send[T any](ctx context.Context, out chan<- T, v T) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case out <- v:
+		return true
+	}
+}
+
+// receive takes the next value from in. It returns false once in is closed or ctx is done.
+func receive[T any](ctx context.Context, in <-chan T) (T, bool) {
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	case v, ok := <-in:
+		return v, ok
+	}
+}
+
+// stage starts a goroutine running process and returns the channel it writes to.
+// The channel is closed once process returns.
+func stage[A, B any](
+	process func(ctx context.Context, in <-chan A, out chan<- B),
+) hw06pipelineexecution.TypedContextStage[A, B] {
+	return func(ctx context.Context, in <-chan A) <-chan B {
+		out := make(chan B)
+		go func() {
+			defer close(out)
+			process(ctx, in, out)
+		}()
+		return out
+	}
+}
+
+// Map applies f to every value.
+func Map[A, B any](f func(A) B) hw06pipelineexecution.TypedContextStage[A, B] {
+	return stage(func(ctx context.Context, in <-chan A, out chan<- B) {
+		for v, ok := receive(ctx, in); ok; v, ok = receive(ctx, in) {
+			if !send(ctx, out, f(v)) {
+				return
+			}
+		}
+	})
+}
+
+// Filter passes only the values keep returns true for.
+func Filter[T any](keep func(T) bool) hw06pipelineexecution.TypedContextStage[T, T] {
+	return stage(func(ctx context.Context, in <-chan T, out chan<- T) {
+		for v, ok := receive(ctx, in); ok; v, ok = receive(ctx, in) {
+			if keep(v) && !send(ctx, out, v) {
+				return
+			}
+		}
+	})
+}
+
+// FlatMap emits all values returned by f one by one.
+func FlatMap[A, B any](f func(A) []B) hw06pipelineexecution.TypedContextStage[A, B] {
+	return stage(func(ctx context.Context, in <-chan A, out chan<- B) {
+		for v, ok := receive(ctx, in); ok; v, ok = receive(ctx, in) {
+			for _, r := range f(v) {
+				if !send(ctx, out, r) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// Reduce folds all values into one starting from initial and emits it once the input is closed.
+// Nothing is emitted if ctx is done first.
+func Reduce[A, B any](initial B, f func(acc B, v A) B) hw06pipelineexecution.TypedContextStage[A, B] {
+	return stage(func(ctx context.Context, in <-chan A, out chan<- B) {
+		acc := initial
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-in:
+				if !ok {
+					send(ctx, out, acc)
+					return
+				}
+				acc = f(acc, v)
+			}
+		}
+	})
+}
+
+// Take passes the first n values and closes the output. It stops reading the input,
+// so the stages before it have to be stopped by cancelling ctx, ExecuteContext does that by itself.
+func Take[T any](n int) hw06pipelineexecution.TypedContextStage[T, T] {
+	return stage(func(ctx context.Context, in <-chan T, out chan<- T) {
+		for i := 0; i < n; i++ {
+			v, ok := receive(ctx, in)
+			if !ok || !send(ctx, out, v) {
+				return
+			}
+		}
+	})
+}
+
+// Skip drops the first n values and passes the rest.
+func Skip[T any](n int) hw06pipelineexecution.TypedContextStage[T, T] {
+	return stage(func(ctx context.Context, in <-chan T, out chan<- T) {
+		skipped := 0
+		for v, ok := receive(ctx, in); ok; v, ok = receive(ctx, in) {
+			if skipped < n {
+				skipped++
+				continue
+			}
+			if !send(ctx, out, v) {
+				return
+			}
+		}
+	})
+}
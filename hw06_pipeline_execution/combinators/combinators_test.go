@@ -0,0 +1,129 @@
+package combinators
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	hw06pipelineexecution "github.com/a-klimenko/go-otus-hw/hw06_pipeline_execution"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// from sends values to a new channel until ctx is done and closes it.
+func from[T any](ctx context.Context, values ...T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for _, v := range values {
+			if !send(ctx, out, v) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// counter sends 0, 1, 2... until ctx is done.
+func counter(ctx context.Context) <-chan int {
+	out := make(chan int)
+	go func() {
+		defer close(out)
+		for i := 0; send(ctx, out, i); i++ {
+		}
+	}()
+	return out
+}
+
+func collect[T any](in <-chan T) []T {
+	var result []T
+	for v := range in {
+		result = append(result, v)
+	}
+	return result
+}
+
+// apply runs stage on values.
+func apply[A, B any](stage hw06pipelineexecution.TypedContextStage[A, B], values ...A) []B {
+	ctx := context.Background()
+	return collect(stage(ctx, from(ctx, values...)))
+}
+
+func TestStages(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("map", func(t *testing.T) {
+		require.Equal(t, []string{"1", "2", "3"}, apply(Map(strconv.Itoa), 1, 2, 3))
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		even := Filter(func(v int) bool { return v%2 == 0 })
+		require.Equal(t, []int{2, 4}, apply(even, 1, 2, 3, 4, 5))
+		require.Nil(t, apply(even, 1, 3))
+	})
+
+	t.Run("flat map", func(t *testing.T) {
+		require.Equal(t, []string{"a", "b", "c", "d"}, apply(FlatMap(strings.Fields), "a b", "", "c d"))
+	})
+
+	t.Run("reduce", func(t *testing.T) {
+		sum := Reduce(0, func(acc, v int) int { return acc + v })
+		require.Equal(t, []int{15}, apply(sum, 1, 2, 3, 4, 5))
+		require.Equal(t, []int{0}, apply(sum))
+	})
+
+	t.Run("take", func(t *testing.T) {
+		require.Equal(t, []int{1, 2}, apply(Take[int](2), 1, 2))
+		require.Equal(t, []int{1}, apply(Take[int](2), 1))
+		require.Nil(t, apply(Take[int](0)))
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		require.Equal(t, []int{3, 4}, apply(Skip[int](2), 1, 2, 3, 4))
+		require.Nil(t, apply(Skip[int](5), 1, 2))
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		stages := map[string]hw06pipelineexecution.TypedContextStage[int, int]{
+			"map":    Map(func(v int) int { return v }),
+			"filter": Filter(func(v int) bool { return true }),
+			"flat":   FlatMap(func(v int) []int { return []int{v, v} }),
+			"reduce": Reduce(0, func(acc, v int) int { return acc + v }),
+			"take":   Take[int](1 << 30),
+			"skip":   Skip[int](10),
+		}
+
+		for name, stage := range stages {
+			t.Run(name, func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				out := stage(ctx, counter(ctx))
+				if name != "reduce" {
+					<-out
+				}
+				cancel()
+				for range out {
+				}
+			})
+		}
+	})
+}
+
+func TestPipelineWithCombinators(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	// Take stops reading the endless input, the pipeline has to stop the stages before it.
+	p := hw06pipelineexecution.ThenContext(hw06pipelineexecution.NewPipeline[int](),
+		Filter(func(v int) bool { return v%3 == 0 }))
+	p2 := hw06pipelineexecution.ThenContext(p, Skip[int](1))
+	p3 := hw06pipelineexecution.ThenContext(p2, Take[int](4))
+	p4 := hw06pipelineexecution.ThenContext(p3, Map(strconv.Itoa))
+
+	// The source is stopped only after the test, the pipeline itself mustn't leak.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e := p4.ExecuteContext(context.Background(), counter(ctx), hw06pipelineexecution.Abort)
+	require.Equal(t, []string{"3", "6", "9", "12"}, collect(e.Out))
+	require.NoError(t, e.Wait())
+}
@@ -0,0 +1,38 @@
+package combinators
+
+import (
+	"container/list"
+	"context"
+
+	hw06pipelineexecution "github.com/a-klimenko/go-otus-hw/hw06_pipeline_execution"
+)
+
+// Distinct drops values equal to one of the last capacity distinct values seen,
+// so memory stays bounded for endless streams. Capacity <= 0 means 1.
+func Distinct[T comparable](capacity int) hw06pipelineexecution.TypedContextStage[T, T] {
+	if capacity <= 0 {
+		capacity = 1
+	}
+
+	return stage(func(ctx context.Context, in <-chan T, out chan<- T) {
+		// The least recently seen value is at the back of the queue.
+		queue := list.New()
+		seen := make(map[T]*list.Element, capacity)
+
+		for v, ok := receive(ctx, in); ok; v, ok = receive(ctx, in) {
+			if e, found := seen[v]; found {
+				queue.MoveToFront(e)
+				continue
+			}
+
+			if queue.Len() == capacity {
+				delete(seen, queue.Remove(queue.Back()).(T))
+			}
+			seen[v] = queue.PushFront(v)
+
+			if !send(ctx, out, v) {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package combinators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestDistinct(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("drops repeated values", func(t *testing.T) {
+		require.Equal(t, []string{"a", "b", "c"}, apply(Distinct[string](10), "a", "b", "a", "c", "b", "c"))
+	})
+
+	t.Run("memory is bounded", func(t *testing.T) {
+		// 1 is forgotten once 2 and 3 are seen.
+		require.Equal(t, []int{1, 2, 3, 1}, apply(Distinct[int](2), 1, 2, 3, 1))
+	})
+
+	t.Run("repeated values are refreshed", func(t *testing.T) {
+		// The second 1 makes 2 the oldest value, so it is forgotten instead of 1.
+		require.Equal(t, []int{1, 2, 3, 2}, apply(Distinct[int](2), 1, 2, 1, 3, 1, 2))
+	})
+}
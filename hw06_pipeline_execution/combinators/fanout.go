@@ -0,0 +1,86 @@
+package combinators
+
+import (
+	"context"
+	"sync"
+)
+
+// Tee copies every value of in to n outputs. A value is taken from in only after all outputs
+// have received the previous one, so the slowest reader sets the pace. All outputs are closed
+// once in is closed or ctx is done.
+func Tee[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		result[i] = outs[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+
+		for v, ok := receive(ctx, in); ok; v, ok = receive(ctx, in) {
+			for _, out := range outs {
+				if !send(ctx, out, v) {
+					return
+				}
+			}
+		}
+	}()
+
+	return result
+}
+
+// Merge passes values of all ins to a single output in the order they arrive.
+// The output is closed once all ins are closed or ctx is done.
+func Merge[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(ins))
+	for _, in := range ins {
+		go func(in <-chan T) {
+			defer wg.Done()
+			for v, ok := receive(ctx, in); ok; v, ok = receive(ctx, in) {
+				if !send(ctx, out, v) {
+					return
+				}
+			}
+		}(in)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// Partition sends the values matching pred to the first output and the rest to the second one.
+// Both outputs have to be read. They are closed once in is closed or ctx is done.
+func Partition[T any](ctx context.Context, in <-chan T, pred func(T) bool) (matched, rest <-chan T) {
+	matchedCh := make(chan T)
+	restCh := make(chan T)
+
+	go func() {
+		defer close(matchedCh)
+		defer close(restCh)
+
+		for v, ok := receive(ctx, in); ok; v, ok = receive(ctx, in) {
+			out := restCh
+			if pred(v) {
+				out = matchedCh
+			}
+			if !send(ctx, out, v) {
+				return
+			}
+		}
+	}()
+
+	return matchedCh, restCh
+}
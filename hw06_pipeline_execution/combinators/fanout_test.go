@@ -0,0 +1,85 @@
+package combinators
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// collectAll reads all channels concurrently.
+func collectAll[T any](ins ...<-chan T) [][]T {
+	result := make([][]T, len(ins))
+	wg := sync.WaitGroup{}
+	wg.Add(len(ins))
+	for i, in := range ins {
+		go func(i int, in <-chan T) {
+			defer wg.Done()
+			result[i] = collect(in)
+		}(i, in)
+	}
+	wg.Wait()
+	return result
+}
+
+func TestTee(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("every output gets every value", func(t *testing.T) {
+		ctx := context.Background()
+		outs := Tee(ctx, from(ctx, 1, 2, 3), 3)
+		require.Equal(t, [][]int{{1, 2, 3}, {1, 2, 3}, {1, 2, 3}}, collectAll(outs...))
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		outs := Tee(ctx, counter(ctx), 2)
+		<-outs[0]
+		cancel()
+		collectAll(outs...)
+	})
+}
+
+func TestMerge(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("all values", func(t *testing.T) {
+		ctx := context.Background()
+		result := collect(Merge(ctx, from(ctx, 1, 2), from(ctx, 3), from[int](ctx)))
+		sort.Ints(result)
+		require.Equal(t, []int{1, 2, 3}, result)
+	})
+
+	t.Run("no inputs", func(t *testing.T) {
+		require.Nil(t, collect(Merge[int](context.Background())))
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		out := Merge(ctx, counter(ctx), counter(ctx))
+		<-out
+		cancel()
+		collect(out)
+	})
+}
+
+func TestPartition(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("split", func(t *testing.T) {
+		ctx := context.Background()
+		even, odd := Partition(ctx, from(ctx, 1, 2, 3, 4, 5), func(v int) bool { return v%2 == 0 })
+		require.Equal(t, [][]int{{2, 4}, {1, 3, 5}}, collectAll(even, odd))
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		even, odd := Partition(ctx, counter(ctx), func(v int) bool { return v%2 == 0 })
+		<-even
+		cancel()
+		collectAll(even, odd)
+	})
+}
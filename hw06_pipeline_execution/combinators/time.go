@@ -0,0 +1,201 @@
+package combinators
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	hw06pipelineexecution "github.com/a-klimenko/go-otus-hw/hw06_pipeline_execution"
+)
+
+var ErrTimeout = errors.New("item processing timed out")
+
+// Throttle delays values, so that they are emitted at least interval apart. Nil clock means the real one.
+func Throttle[T any](interval time.Duration, clock Clock) hw06pipelineexecution.TypedContextStage[T, T] {
+	clock = clockOrReal(clock)
+
+	return stage(func(ctx context.Context, in <-chan T, out chan<- T) {
+		var next time.Time // the earliest time of the next emission
+		for v, ok := receive(ctx, in); ok; v, ok = receive(ctx, in) {
+			if wait := next.Sub(clock.Now()); !next.IsZero() && wait > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-clock.After(wait):
+				}
+			}
+
+			if !send(ctx, out, v) {
+				return
+			}
+			next = clock.Now().Add(interval)
+		}
+	})
+}
+
+// Debounce emits a value only once no other value has arrived for quiet, later values replace earlier ones.
+// The pending value is emitted when the input is closed. Nil clock means the real one.
+func Debounce[T any](quiet time.Duration, clock Clock) hw06pipelineexecution.TypedContextStage[T, T] {
+	clock = clockOrReal(clock)
+
+	return stage(func(ctx context.Context, in <-chan T, out chan<- T) {
+		var pending T
+		var timeout <-chan time.Time // nil while nothing is pending
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timeout:
+				timeout = nil
+				if !send(ctx, out, pending) {
+					return
+				}
+			case v, ok := <-in:
+				if !ok {
+					if timeout != nil {
+						send(ctx, out, pending)
+					}
+					return
+				}
+				pending = v
+				timeout = clock.After(quiet)
+			}
+		}
+	})
+}
+
+// Sample emits the latest value received during every interval, intervals without new values emit nothing.
+// A value received during the last unfinished interval is dropped. Nil clock means the real one.
+func Sample[T any](interval time.Duration, clock Clock) hw06pipelineexecution.TypedContextStage[T, T] {
+	clock = clockOrReal(clock)
+
+	return stage(func(ctx context.Context, in <-chan T, out chan<- T) {
+		var latest T
+		fresh := false
+		tick := clock.After(interval)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+				tick = clock.After(interval)
+				if fresh {
+					fresh = false
+					if !send(ctx, out, latest) {
+						return
+					}
+				}
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				latest = v
+				fresh = true
+			}
+		}
+	})
+}
+
+// Timeout limits the time f may spend on a single value. The returned function fails with ErrTimeout
+// if f hasn't returned in time, it is meant for hw06pipelineexecution.ThenErr, so slow items are handled
+// by the error policy of the pipeline. The context of f is cancelled on timeout or when ctx is done;
+// f keeps running in its own goroutine until it returns. ThenErr functions don't get the context
+// of the pipeline: pass the one of ExecuteContext to cancel f with it, an abort of the pipeline
+// cancels f only if the caller cancels ctx then. TimeoutDrop is stopped with the pipeline.
+// Nil clock means the real one.
+func Timeout[A, B any](
+	ctx context.Context, d time.Duration, clock Clock, f func(ctx context.Context, v A) (B, error),
+) func(A) (B, error) {
+	clock = clockOrReal(clock)
+
+	return func(v A) (B, error) {
+		return callWithTimeout(ctx, d, clock, v, f)
+	}
+}
+
+// TimeoutDrop is like Timeout, but values f hasn't processed in time are silently dropped.
+// It is a stage for hw06pipelineexecution.ThenContext, f is cancelled when the pipeline is stopped.
+func TimeoutDrop[A, B any](
+	d time.Duration, clock Clock, f func(ctx context.Context, v A) B,
+) hw06pipelineexecution.TypedContextStage[A, B] {
+	clock = clockOrReal(clock)
+	call := func(ctx context.Context, v A) (B, error) {
+		return f(ctx, v), nil
+	}
+
+	return stage(func(ctx context.Context, in <-chan A, out chan<- B) {
+		for v, ok := receive(ctx, in); ok; v, ok = receive(ctx, in) {
+			r, err := callWithTimeout(ctx, d, clock, v, call)
+			if errors.Is(err, ErrTimeout) {
+				continue
+			}
+			if err != nil || !send(ctx, out, r) {
+				return
+			}
+		}
+	})
+}
+
+func callWithTimeout[A, B any](
+	ctx context.Context, d time.Duration, clock Clock, v A, f func(ctx context.Context, v A) (B, error),
+) (B, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		v   B
+		err error
+	}
+	done := make(chan result, 1) // f mustn't block on sending if nobody waits anymore
+	go func() {
+		r, err := f(ctx, v)
+		done <- result{v: r, err: err}
+	}()
+
+	var zero B
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-clock.After(d):
+		return zero, ErrTimeout
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// Ticker sends the time of the clock every interval until ctx is done. Ticks are not accumulated
+// while nobody reads the channel. Nil clock means the real one. Like time.NewTicker, it panics
+// if interval isn't positive.
+func Ticker(ctx context.Context, interval time.Duration, clock Clock) <-chan time.Time {
+	if interval <= 0 {
+		panic("combinators: non-positive interval for Ticker")
+	}
+	clock = clockOrReal(clock)
+	out := make(chan time.Time)
+
+	go func() {
+		defer close(out)
+
+		next := clock.Now().Add(interval)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-clock.After(next.Sub(clock.Now())):
+			}
+
+			if !send(ctx, out, clock.Now()) {
+				return
+			}
+
+			// Skip the ticks missed while the reader was busy, but keep the phase.
+			if now := clock.Now(); !next.After(now) {
+				next = next.Add((now.Sub(next)/interval + 1) * interval)
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,197 @@
+package combinators
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// waitTimers waits until the stage under test has started waiting for n timers.
+func waitTimers(t *testing.T, clock *manualClock, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool { return clock.Timers() == n }, time.Second, time.Millisecond)
+}
+
+func TestThrottle(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	clock := &manualClock{}
+	in := make(chan int)
+	out := Throttle[int](time.Second, clock)(context.Background(), in)
+
+	go func() {
+		defer close(in)
+		for i := 1; i <= 3; i++ {
+			in <- i
+		}
+	}()
+
+	require.Equal(t, 1, <-out)
+
+	waitTimers(t, clock, 1)
+	clock.Advance(time.Second)
+	require.Equal(t, 2, <-out)
+
+	waitTimers(t, clock, 1)
+	clock.Advance(time.Second)
+	require.Equal(t, 3, <-out)
+
+	require.Nil(t, collect(out))
+}
+
+func TestDebounce(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	clock := &manualClock{}
+	in := make(chan int)
+	out := Debounce[int](time.Second, clock)(context.Background(), in)
+
+	in <- 1
+	clock.Advance(500 * time.Millisecond)
+	in <- 2
+	waitTimers(t, clock, 2)
+
+	// The timer of the first value fires, but it was replaced by the second one.
+	clock.Advance(500 * time.Millisecond)
+	waitTimers(t, clock, 1)
+	clock.Advance(500 * time.Millisecond)
+	require.Equal(t, 2, <-out)
+
+	in <- 3
+	close(in)
+	require.Equal(t, []int{3}, collect(out))
+}
+
+func TestSample(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	clock := &manualClock{}
+	in := make(chan int)
+	ctx, cancel := context.WithCancel(context.Background())
+	out := Sample[int](time.Second, clock)(ctx, in)
+
+	waitTimers(t, clock, 1)
+	in <- 1
+	in <- 2
+	clock.Advance(time.Second)
+	require.Equal(t, 2, <-out)
+
+	// Nothing new during the next interval.
+	waitTimers(t, clock, 1)
+	clock.Advance(time.Second)
+
+	waitTimers(t, clock, 1)
+	in <- 3
+	clock.Advance(time.Second)
+	require.Equal(t, 3, <-out)
+
+	cancel()
+	require.Nil(t, collect(out))
+}
+
+func TestTimeout(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	clock := &manualClock{}
+	cancelled := make(chan struct{})
+	f := Timeout(context.Background(), time.Second, clock, func(ctx context.Context, v int) (int, error) {
+		if v > 0 {
+			return v * 2, nil
+		}
+		<-ctx.Done()
+		close(cancelled)
+		return 0, ctx.Err()
+	})
+
+	v, err := f(2)
+	require.NoError(t, err)
+	require.Equal(t, 4, v)
+
+	errCh := make(chan error)
+	go func() {
+		_, err := f(-1)
+		errCh <- err
+	}()
+
+	// The timer of the first call is still pending.
+	waitTimers(t, clock, 2)
+	clock.Advance(time.Second)
+	require.ErrorIs(t, <-errCh, ErrTimeout)
+	<-cancelled
+}
+
+func TestTimeoutCancelled(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	f := Timeout(ctx, time.Hour, &manualClock{}, func(ctx context.Context, v int) (int, error) {
+		close(started)
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+
+	errCh := make(chan error)
+	go func() {
+		_, err := f(1)
+		errCh <- err
+	}()
+
+	<-started
+	cancel()
+	require.ErrorIs(t, <-errCh, context.Canceled)
+}
+
+func TestTimeoutDrop(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	clock := &manualClock{}
+	stage := TimeoutDrop(time.Second, clock, func(ctx context.Context, v int) int {
+		if v%2 != 0 {
+			<-ctx.Done()
+		}
+		return v
+	})
+
+	in := make(chan int)
+	out := stage(context.Background(), in)
+	go func() {
+		defer close(in)
+		in <- 1
+		in <- 2
+	}()
+
+	waitTimers(t, clock, 1)
+	clock.Advance(time.Second)
+	require.Equal(t, []int{2}, collect(out))
+}
+
+func TestTicker(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	clock := &manualClock{}
+	start := clock.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := Ticker(ctx, time.Second, clock)
+
+	waitTimers(t, clock, 1)
+	clock.Advance(time.Second)
+	require.Equal(t, start.Add(time.Second), <-ticks)
+
+	// A late tick is sent right away, the missed ones are skipped and the phase is kept.
+	waitTimers(t, clock, 1)
+	clock.Advance(2500 * time.Millisecond)
+	require.Equal(t, start.Add(3500*time.Millisecond), <-ticks)
+	waitTimers(t, clock, 1)
+	clock.Advance(500 * time.Millisecond)
+	require.Equal(t, start.Add(4*time.Second), <-ticks)
+
+	cancel()
+	require.Nil(t, collect(ticks))
+
+	require.Panics(t, func() { Ticker(context.Background(), 0, clock) })
+	require.Panics(t, func() { Ticker(context.Background(), -time.Second, clock) })
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	hw06pipelineexecution "github.com/a-klimenko/go-otus-hw/hw06_pipeline_execution"
+	"gopkg.in/yaml.v3"
+)
+
+// Error is a problem of a description at a position.
+type Error struct {
+	File   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+}
+
+// ErrorList is every problem found in a description, in the order of positions.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	lines := make([]string, len(l))
+	for i, e := range l {
+		lines[i] = e.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// LoadFile is Load reading the description from path.
+func (r *Registry) LoadFile(path string) ([]hw06pipelineexecution.Stage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.Load(path, data)
+}
+
+// Load builds the stages described by data for hw06pipelineexecution.ExecutePipeline. The description
+// is YAML or JSON, file is used in errors only:
+//
+//	stages:
+//	  - name: multiply
+//	    params:
+//	      by: 2
+//	  - name: format
+//
+// Problems of the description are returned as ErrorList.
+func (r *Registry) Load(file string, data []byte) ([]hw06pipelineexecution.Stage, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	l := loader{registry: r, file: file}
+	stages := l.load(&doc)
+	if len(l.errs) > 0 {
+		sort.SliceStable(l.errs, func(i, j int) bool {
+			a, b := l.errs[i], l.errs[j]
+			return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+		})
+		return nil, l.errs
+	}
+	return stages, nil
+}
+
+type loader struct {
+	registry *Registry
+	file     string
+	errs     ErrorList
+}
+
+func (l *loader) errorf(n *yaml.Node, format string, args ...interface{}) {
+	l.errs = append(l.errs, &Error{File: l.file, Line: n.Line, Column: n.Column, Msg: fmt.Sprintf(format, args...)})
+}
+
+// fields returns the values of a mapping by keys, reporting unknown keys.
+func (l *loader) fields(n *yaml.Node, what string, known ...string) map[string]*yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		l.errorf(n, "%s must be a mapping", what)
+		return nil
+	}
+
+	fields := make(map[string]*yaml.Node, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		switch {
+		case known != nil && !contains(known, key.Value):
+			l.errorf(key, "unknown field %q in %s", key.Value, what)
+		case fields[key.Value] != nil:
+			l.errorf(key, "duplicate field %q in %s", key.Value, what)
+		default:
+			fields[key.Value] = value
+		}
+	}
+	return fields
+}
+
+func (l *loader) load(doc *yaml.Node) []hw06pipelineexecution.Stage {
+	if len(doc.Content) == 0 {
+		l.errs = append(l.errs, &Error{File: l.file, Line: 1, Column: 1, Msg: "empty description"})
+		return nil
+	}
+
+	root := l.fields(doc.Content[0], "the description", "stages")
+	list := root["stages"]
+	if root != nil && list == nil {
+		l.errorf(doc.Content[0], "no stages")
+	}
+	if list == nil {
+		return nil
+	}
+	if list.Kind != yaml.SequenceNode {
+		l.errorf(list, "stages must be a list")
+		return nil
+	}
+
+	stages := make([]hw06pipelineexecution.Stage, 0, len(list.Content))
+	var prev *yaml.Node      // the name of the previous known stage
+	var prevOut reflect.Type // the output of the previous known stage
+	for _, n := range list.Content {
+		fields := l.fields(n, "a stage", "name", "params")
+		name := fields["name"]
+		if fields != nil && name == nil {
+			l.errorf(n, "stage without a name")
+		}
+		f, ok := Factory{}, false
+		if name != nil {
+			f, ok = l.registry.factories[name.Value]
+			if name.Kind != yaml.ScalarNode || !ok {
+				l.errorf(name, "unknown stage %q", name.Value)
+			}
+		}
+		if !ok {
+			// Types can't be checked against an unknown stage.
+			prev = nil
+			continue
+		}
+
+		params, ok := l.params(name.Value, f.Params, n, fields["params"])
+		if prev != nil && !assignable(prevOut, f.In) {
+			l.errorf(name, "stage %q takes %s, but %q at line %d emits %s",
+				name.Value, typeName(f.In), prev.Value, prev.Line, typeName(prevOut))
+		}
+		prev, prevOut = name, f.Out
+		if !ok {
+			continue
+		}
+
+		stage, err := f.New(params)
+		if err != nil {
+			l.errorf(name, "stage %q: %v", name.Value, err)
+			continue
+		}
+		stages = append(stages, stage)
+	}
+
+	return stages
+}
+
+// params decodes the parameters of a stage. It reports whether they are valid.
+func (l *loader) params(stage string, specs []Param, n, values *yaml.Node) (Params, bool) {
+	errs := len(l.errs)
+
+	var fields map[string]*yaml.Node
+	if values != nil {
+		known := make([]string, len(specs))
+		for i, spec := range specs {
+			known[i] = spec.Name
+		}
+		fields = l.fields(values, fmt.Sprintf("the params of stage %q", stage), known...)
+	}
+
+	params := make(Params, len(specs))
+	for _, spec := range specs {
+		value := fields[spec.Name]
+		switch {
+		case value != nil:
+			v, err := decode(value, spec.Type)
+			if err != nil {
+				l.errorf(value, "parameter %q of stage %q: %v", spec.Name, stage, err)
+				continue
+			}
+			params[spec.Name] = v
+		case spec.Required:
+			l.errorf(n, "stage %q requires parameter %q", stage, spec.Name)
+		case spec.Default != nil:
+			params[spec.Name] = spec.Default
+		}
+	}
+
+	return params, len(l.errs) == errs
+}
+
+func decode(n *yaml.Node, t ParamType) (interface{}, error) {
+	tags := map[ParamType][]string{
+		String:   {"!!str"},
+		Int:      {"!!int"},
+		Float:    {"!!float", "!!int"},
+		Bool:     {"!!bool"},
+		Duration: {"!!str"},
+	}
+	if n.Kind != yaml.ScalarNode || !contains(tags[t], n.Tag) {
+		return nil, fmt.Errorf("must be %s", t)
+	}
+
+	switch t {
+	case Int:
+		var v int
+		err := n.Decode(&v)
+		return v, err
+	case Float:
+		var v float64
+		err := n.Decode(&v)
+		return v, err
+	case Bool:
+		var v bool
+		err := n.Decode(&v)
+		return v, err
+	case Duration:
+		return time.ParseDuration(n.Value)
+	default:
+		return n.Value, nil
+	}
+}
+
+// assignable reports whether values of type out may be passed to a stage taking in.
+// Nil means any, such stages aren't checked.
+func assignable(out, in reflect.Type) bool {
+	return out == nil || in == nil || out.AssignableTo(in)
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "any value"
+	}
+	return t.String()
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("files", func(t *testing.T) {
+		for _, path := range []string{"testdata/pipeline.yaml", "testdata/pipeline.json"} {
+			stages, err := newTestRegistry(t).LoadFile(path)
+			require.NoError(t, err, path)
+			require.Equal(t, []string{"n=102", "n=104", "n=106"}, execute(stages, 1, 2, 3), path)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := newTestRegistry(t).LoadFile("testdata/missing.yaml")
+		require.Error(t, err)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		stages, err := newTestRegistry(t).Load("test.yaml", []byte("stages: [{name: add}, {name: format}]"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"2"}, execute(stages, 1))
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := newTestRegistry(t).Load("test.json", []byte(`{"stages": [}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "test.json")
+	})
+
+	t.Run("malformed yaml", func(t *testing.T) {
+		for _, data := range []string{"#\n-\n{", "stages: [a\n  - b: {", "\t- name"} {
+			stages, err := newTestRegistry(t).Load("test.yaml", []byte(data))
+			require.Nil(t, stages, data)
+			require.Error(t, err, data)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		data   string
+		errors []string
+	}{
+		{
+			name:   "empty",
+			data:   "",
+			errors: []string{"test.yaml:1:1: empty description"},
+		},
+		{
+			name:   "no stages",
+			data:   "stagez: []",
+			errors: []string{`test.yaml:1:1: unknown field "stagez" in the description`, "test.yaml:1:1: no stages"},
+		},
+		{
+			name:   "not a list",
+			data:   "stages: add",
+			errors: []string{"test.yaml:1:9: stages must be a list"},
+		},
+		{
+			name: "unknown stage",
+			data: `
+stages:
+  - name: add
+  - name: divide
+  - {params: {by: 2}}
+  - 42
+`,
+			errors: []string{
+				`test.yaml:4:11: unknown stage "divide"`,
+				"test.yaml:5:5: stage without a name",
+				"test.yaml:6:5: a stage must be a mapping",
+			},
+		},
+		{
+			name: "params",
+			data: `
+stages:
+  - name: multiply
+  - name: multiply
+    params:
+      by: two
+      times: 2
+  - name: delay
+    params: {for: 1 minute}
+  - name: delay
+    params: {for: -1s}
+  - name: format
+    params: {prefix: [a]}
+`,
+			errors: []string{
+				`test.yaml:3:5: stage "multiply" requires parameter "by"`,
+				`test.yaml:6:11: parameter "by" of stage "multiply": must be int`,
+				`test.yaml:7:7: unknown field "times" in the params of stage "multiply"`,
+				`test.yaml:9:19: parameter "for" of stage "delay": time: unknown unit " minute" in duration "1 minute"`,
+				`test.yaml:10:11: stage "delay": must not be negative`,
+				`test.yaml:13:22: parameter "prefix" of stage "format": must be string`,
+			},
+		},
+		{
+			name: "types",
+			data: `{
+	"stages": [
+		{"name": "format"},
+		{"name": "add"},
+		{"name": "nope"},
+		{"name": "format"},
+		{"name": "delay", "params": {"for": "1ms"}},
+		{"name": "add"}
+	]
+}`,
+			errors: []string{
+				`test.yaml:4:12: stage "add" takes int, but "format" at line 3 emits string`,
+				`test.yaml:5:12: unknown stage "nope"`,
+			},
+		},
+		{
+			name: "incompatible",
+			data: `
+stages:
+  - name: format
+  - name: multiply
+    params: {by: 2}
+`,
+			errors: []string{`test.yaml:4:11: stage "multiply" takes int, but "format" at line 3 emits string`},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			stages, err := newTestRegistry(t).Load("test.yaml", []byte(tc.data))
+			require.Nil(t, stages)
+
+			var list ErrorList
+			require.True(t, errors.As(err, &list))
+			messages := make([]string, len(list))
+			for i, e := range list {
+				messages[i] = e.Error()
+			}
+			require.Equal(t, tc.errors, messages)
+		})
+	}
+}
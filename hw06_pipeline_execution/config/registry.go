@@ -0,0 +1,141 @@
+// Package config builds chains of hw06pipelineexecution.Stage from YAML or JSON descriptions,
+// so pipelines can be changed without recompiling. Stages are made by named factories
+// of a Registry, the loader validates names, parameters and types of adjacent stages.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	hw06pipelineexecution "github.com/a-klimenko/go-otus-hw/hw06_pipeline_execution"
+)
+
+var (
+	ErrDuplicateStage = errors.New("stage is already registered")
+	ErrWrongType      = errors.New("value of a wrong type")
+)
+
+// ParamType is the type of a stage parameter.
+type ParamType int
+
+const (
+	String ParamType = iota
+	Int
+	Float
+	Bool
+	// Duration is written as a string understood by time.ParseDuration, e.g. "1m30s".
+	Duration
+)
+
+func (t ParamType) String() string {
+	switch t {
+	case String:
+		return "string"
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	case Bool:
+		return "bool"
+	case Duration:
+		return "duration"
+	default:
+		return fmt.Sprintf("ParamType(%d)", int(t))
+	}
+}
+
+// Param describes a parameter of a stage factory.
+type Param struct {
+	Name     string
+	Type     ParamType
+	Required bool
+	// Default is used if an optional parameter is missing, it must be of the Go type of Params values.
+	Default interface{}
+}
+
+// Params are the values of the parameters of a stage: string, int, float64, bool or time.Duration
+// according to the ParamType. Parameters are validated by the loader, so the getters don't fail.
+type Params map[string]interface{}
+
+func (p Params) String(name string) string {
+	v, _ := p[name].(string)
+	return v
+}
+
+func (p Params) Int(name string) int {
+	v, _ := p[name].(int)
+	return v
+}
+
+func (p Params) Float(name string) float64 {
+	v, _ := p[name].(float64)
+	return v
+}
+
+func (p Params) Bool(name string) bool {
+	v, _ := p[name].(bool)
+	return v
+}
+
+func (p Params) Duration(name string) time.Duration {
+	v, _ := p[name].(time.Duration)
+	return v
+}
+
+// Factory makes stages of one kind.
+type Factory struct {
+	// In and Out are the types of values the stage takes and emits, nil means any.
+	// The loader checks that Out of a stage is assignable to In of the next one unless either is nil.
+	In, Out reflect.Type
+	Params  []Param
+	// New makes a stage, an error is reported at the position of the stage in the description.
+	New func(params Params) (hw06pipelineexecution.Stage, error)
+}
+
+// Map returns a factory of stages applying the function made by newF to every value.
+// The stages are hw06pipelineexecution.ItemStage, so they stop with the pipeline. A value of a type
+// other than A, e.g. in the input of the pipeline, which the loader can't check, fails with ErrWrongType.
+func Map[A, B any](params []Param, newF func(params Params) (func(A) B, error)) Factory {
+	in := reflect.TypeOf((*A)(nil)).Elem()
+	return Factory{
+		In:     in,
+		Out:    reflect.TypeOf((*B)(nil)).Elem(),
+		Params: params,
+		New: func(params Params) (hw06pipelineexecution.Stage, error) {
+			f, err := newF(params)
+			if err != nil {
+				return nil, err
+			}
+
+			return hw06pipelineexecution.ItemStage(func(v interface{}) (interface{}, error) {
+				a, ok := v.(A)
+				if !ok {
+					return nil, fmt.Errorf("%w: %T instead of %s", ErrWrongType, v, in)
+				}
+				return f(a), nil
+			}), nil
+		},
+	}
+}
+
+// Registry holds stage factories by name.
+type Registry struct {
+	factories map[string]Factory
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register adds a factory, it fails with ErrDuplicateStage if the name is taken.
+func (r *Registry) Register(name string, f Factory) error {
+	if _, ok := r.factories[name]; ok {
+		return fmt.Errorf("%q: %w", name, ErrDuplicateStage)
+	}
+
+	r.factories[name] = f
+	return nil
+}
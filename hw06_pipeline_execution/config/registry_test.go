@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	hw06pipelineexecution "github.com/a-klimenko/go-otus-hw/hw06_pipeline_execution"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+var errNegative = errors.New("must not be negative")
+
+// newTestRegistry registers:
+//   - multiply: int -> int, required int "by";
+//   - add: int -> int, optional int "value" defaulting to 1;
+//   - format: int -> string, optional string "prefix";
+//   - delay: any -> any, required duration "for", it is validated by the factory;
+//   - scale: float64 -> float64, required float "factor" and optional bool "negate".
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+
+	r := NewRegistry()
+	require.NoError(t, r.Register("multiply", Map([]Param{{Name: "by", Type: Int, Required: true}},
+		func(params Params) (func(int) int, error) {
+			by := params.Int("by")
+			return func(v int) int { return v * by }, nil
+		})))
+	require.NoError(t, r.Register("add", Map([]Param{{Name: "value", Type: Int, Default: 1}},
+		func(params Params) (func(int) int, error) {
+			value := params.Int("value")
+			return func(v int) int { return v + value }, nil
+		})))
+	require.NoError(t, r.Register("format", Map([]Param{{Name: "prefix", Type: String}},
+		func(params Params) (func(int) string, error) {
+			prefix := params.String("prefix")
+			return func(v int) string { return prefix + strconv.Itoa(v) }, nil
+		})))
+	require.NoError(t, r.Register("delay", Factory{
+		Params: []Param{{Name: "for", Type: Duration, Required: true}},
+		New: func(params Params) (hw06pipelineexecution.Stage, error) {
+			d := params.Duration("for")
+			if d < 0 {
+				return nil, errNegative
+			}
+			return func(in hw06pipelineexecution.In) hw06pipelineexecution.Out {
+				out := make(hw06pipelineexecution.Bi)
+				go func() {
+					defer close(out)
+					for v := range in {
+						time.Sleep(d)
+						out <- v
+					}
+				}()
+				return out
+			}, nil
+		},
+	}))
+	require.NoError(t, r.Register("scale", Map(
+		[]Param{{Name: "factor", Type: Float, Required: true}, {Name: "negate", Type: Bool}},
+		func(params Params) (func(float64) float64, error) {
+			factor := params.Float("factor")
+			if params.Bool("negate") {
+				factor = -factor
+			}
+			return func(v float64) float64 { return v * factor }, nil
+		})))
+
+	return r
+}
+
+func execute(stages []hw06pipelineexecution.Stage, values ...interface{}) []string {
+	in := make(hw06pipelineexecution.Bi)
+	go func() {
+		defer close(in)
+		for _, v := range values {
+			in <- v
+		}
+	}()
+
+	result := make([]string, 0, len(values))
+	for v := range hw06pipelineexecution.ExecutePipeline(in, nil, stages...) {
+		result = append(result, fmt.Sprint(v))
+	}
+	return result
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("duplicate", func(t *testing.T) {
+		r := newTestRegistry(t)
+		err := r.Register("add", Factory{})
+		require.ErrorIs(t, err, ErrDuplicateStage)
+	})
+
+	t.Run("map", func(t *testing.T) {
+		stages, err := newTestRegistry(t).Load("test.yaml", []byte(`
+stages:
+  - name: scale
+    params: {factor: 1.5, negate: true}
+  - name: scale
+    params: {factor: 2}
+`))
+		require.NoError(t, err)
+		require.Equal(t, []string{"-3", "-6"}, execute(stages, 1.0, 2.0))
+	})
+
+	t.Run("map of a wrong type", func(t *testing.T) {
+		stages, err := newTestRegistry(t).Load("test.yaml", []byte("stages: [{name: add}, {name: format}]"))
+		require.NoError(t, err)
+
+		in := make(hw06pipelineexecution.Bi, 2)
+		in <- 1
+		in <- "two"
+		close(in)
+		e := hw06pipelineexecution.NewUntypedPipeline(stages...).ExecuteErr(in, nil, hw06pipelineexecution.Abort)
+		for range e.Out {
+		}
+		require.ErrorIs(t, e.Wait(), ErrWrongType)
+		require.Contains(t, e.Wait().Error(), "string instead of int")
+	})
+
+	t.Run("map stops with the pipeline", func(t *testing.T) {
+		defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+		stages, err := newTestRegistry(t).Load("test.yaml", []byte("stages: [{name: add}, {name: format}]"))
+		require.NoError(t, err)
+
+		in := make(hw06pipelineexecution.Bi)
+		done := make(hw06pipelineexecution.Bi)
+		out := hw06pipelineexecution.ExecutePipeline(in, done, stages...)
+		in <- 1
+		in <- 2
+		close(done)
+		for range out {
+		}
+		close(in)
+	})
+
+	t.Run("param type names", func(t *testing.T) {
+		require.Equal(t, "duration", Duration.String())
+		require.Equal(t, "ParamType(10)", ParamType(10).String())
+	})
+}
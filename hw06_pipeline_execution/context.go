@@ -0,0 +1,27 @@
+package hw06pipelineexecution
+
+import "context"
+
+// ContextStage is a Stage that should stop its work once ctx is done.
+type ContextStage func(ctx context.Context, in In) (out Out)
+
+// TypedContextStage is a ContextStage with the types of its input and output checked by the compiler.
+type TypedContextStage[A, B any] func(ctx context.Context, in <-chan A) (out <-chan B)
+
+// ExecutePipelineContext is like ExecutePipeline, but the pipeline is stopped when ctx is done.
+// Wait of the result returns ctx.Err() if the pipeline didn't finish before that.
+func ExecutePipelineContext(ctx context.Context, in In, stages ...ContextStage) *Execution[interface{}] {
+	p := NewPipeline[interface{}]()
+	for _, stage := range stages {
+		p = ThenContext(p, TypedContextStage[interface{}, interface{}](stage))
+	}
+
+	return p.ExecuteContext(ctx, in, Abort)
+}
+
+// ThenContext is like Then for stages receiving the context of the pipeline.
+func ThenContext[A, B, C any](p Pipeline[A, B], stage TypedContextStage[B, C]) Pipeline[A, C] {
+	return extend(p, func(x *execution, in <-chan B) <-chan C {
+		return stage(x.ctx, in)
+	})
+}
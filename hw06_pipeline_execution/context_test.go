@@ -0,0 +1,139 @@
+package hw06pipelineexecution
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+type ctxKey struct{}
+
+// ctxStage makes a stage applying f to every value until ctx is done.
+func ctxStage(f func(ctx context.Context, v interface{}) interface{}) ContextStage {
+	return func(ctx context.Context, in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+			for v := range in {
+				select {
+				case <-ctx.Done():
+					return
+				case out <- f(ctx, v):
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// endless sends increasing numbers until ctx is done.
+func endless(ctx context.Context) Bi {
+	in := make(Bi)
+	go func() {
+		defer close(in)
+		for i := 0; ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case in <- i:
+			}
+		}
+	}()
+	return in
+}
+
+func TestExecutePipelineContext(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	t.Run("stages receive the context", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), ctxKey{}, 10)
+		in := make(Bi)
+		go func() {
+			defer close(in)
+			for i := 1; i <= 3; i++ {
+				in <- i
+			}
+		}()
+
+		e := ExecutePipelineContext(ctx, in, ctxStage(func(ctx context.Context, v interface{}) interface{} {
+			return v.(int) * ctx.Value(ctxKey{}).(int)
+		}))
+
+		result := make([]int, 0, 3)
+		for v := range e.Out {
+			result = append(result, v.(int))
+		}
+
+		require.NoError(t, e.Wait())
+		require.Equal(t, []int{10, 20, 30}, result)
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		slow := ctxStage(func(ctx context.Context, v interface{}) interface{} {
+			select {
+			case <-ctx.Done():
+			case <-time.After(10 * time.Millisecond):
+			}
+			return v
+		})
+
+		start := time.Now()
+		e := ExecutePipelineContext(ctx, endless(ctx), slow, slow)
+		for range e.Out {
+		}
+
+		require.ErrorIs(t, e.Wait(), context.DeadlineExceeded)
+		require.Less(t, int64(time.Since(start)), int64(time.Second))
+	})
+
+	t.Run("already cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		e := ExecutePipelineContext(ctx, endless(ctx))
+		for range e.Out {
+		}
+		require.ErrorIs(t, e.Wait(), context.Canceled)
+	})
+
+	t.Run("abort cancels the context of stages", func(t *testing.T) {
+		errStop := errors.New("stop")
+		stageCtx := make(chan context.Context, 1)
+
+		p := ThenContext(NewPipeline[int](), func(ctx context.Context, in <-chan int) <-chan int {
+			stageCtx <- ctx
+			out := make(chan int)
+			go func() {
+				defer close(out)
+				for i := 0; ; i++ {
+					select {
+					case <-ctx.Done():
+						return
+					case out <- i:
+					}
+				}
+			}()
+			return out
+		})
+		p = ThenErr(p, func(v int) (int, error) {
+			if v == 3 {
+				return 0, errStop
+			}
+			return v, nil
+		})
+
+		e := p.ExecuteContext(context.Background(), make(chan int), Abort)
+		for range e.Out {
+		}
+
+		require.ErrorIs(t, e.Wait(), errStop)
+		require.ErrorIs(t, (<-stageCtx).Err(), context.Canceled)
+	})
+}
@@ -0,0 +1,246 @@
+package hw06pipelineexecution
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ErrorPolicy tells a pipeline what to do when a ThenErr stage fails on an item.
+type ErrorPolicy int
+
+const (
+	// Abort stops the whole pipeline on the first error, Wait returns it.
+	Abort ErrorPolicy = iota
+	// DeadLetter sends failed items to Execution.DeadLetters, the pipeline goes on.
+	DeadLetter
+)
+
+// ItemError is a failure of the stage at position Stage of the pipeline on Item.
+type ItemError struct {
+	Stage int
+	Item  interface{}
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("stage %d failed on %v: %v", e.Stage, e.Item, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// execution is the state shared by the stages of a single run of a pipeline.
+type execution struct {
+	ctx      context.Context // passed to ThenContext stages, cancelled when the pipeline is stopped
+	cancel   context.CancelFunc
+	done     In // closed when the pipeline is stopped for any reason or has finished
+	draining In // closed when the input mustn't be read anymore, it is done unless the mode is Drain
+	policy   ErrorPolicy
+	dead     chan *ItemError
+	wg       sync.WaitGroup // goroutines of ThenErr stages
+	aborted  chan struct{}
+	finished chan struct{} // closed when the output is closed
+	stopped  chan struct{} // closed after done
+	ended    chan struct{} // closed when all ThenErr stages have stopped after finished
+
+	stages  int
+	metrics *runMetrics // nil if the pipeline isn't instrumented
+	tracer  *Tracer     // nil if values aren't traced
+	buffers map[int]Buffer
+
+	abortOnce sync.Once
+	mu        sync.Mutex
+	err       error // the first stage error or the error of the parent context
+	dropped   uint64
+}
+
+func newExecution(ctx context.Context, done In, policy ErrorPolicy, mode ShutdownMode) *execution {
+	merged := make(Bi)
+	draining := merged
+	if mode == Drain {
+		draining = make(Bi)
+	}
+	x := &execution{
+		done:     merged,
+		draining: draining,
+		policy:   policy,
+		aborted:  make(chan struct{}),
+		finished: make(chan struct{}),
+		stopped:  make(chan struct{}),
+		ended:    make(chan struct{}),
+	}
+	if mode == Drain {
+		// Stages go on after the parent context is done, till the values in flight are processed.
+		x.ctx, x.cancel = context.WithCancel(detachedContext{ctx})
+	} else {
+		x.ctx, x.cancel = context.WithCancel(ctx)
+	}
+	if policy == DeadLetter {
+		x.dead = make(chan *ItemError)
+	}
+
+	go func() {
+		select {
+		case <-done:
+		case <-x.aborted:
+		case <-ctx.Done():
+		case <-x.finished:
+			// Stages before one that has stopped early, like Take, may still be waiting to send.
+		}
+		if mode == Drain {
+			close(draining)
+			select {
+			case <-x.aborted:
+			case <-x.finished:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			// The output might have been cut short even if the pipeline has just finished.
+			x.setErr(err)
+		}
+		close(merged)
+		x.cancel()
+		close(x.stopped)
+	}()
+
+	return x
+}
+
+// setErr remembers err unless there is an error already.
+func (x *execution) setErr(err error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.err == nil {
+		x.err = err
+	}
+}
+
+// fail handles an error of a stage. It reports whether the stage may go on with the next item.
+func (x *execution) fail(err *ItemError) bool {
+	if x.policy == DeadLetter {
+		select {
+		case <-x.done:
+			return false
+		case x.dead <- err:
+			return true
+		}
+	}
+
+	x.abort(err)
+	return false
+}
+
+// abort stops the pipeline with err unless it has been aborted already.
+func (x *execution) abort(err error) {
+	x.abortOnce.Do(func() {
+		x.setErr(err)
+		close(x.aborted)
+	})
+}
+
+// end must be called once the output of the pipeline is closed.
+func (x *execution) end() {
+	x.metrics.stop()
+	close(x.finished)
+	<-x.stopped
+
+	x.wg.Wait()
+	if x.dead != nil {
+		close(x.dead)
+	}
+	close(x.ended)
+}
+
+// Execution is a running pipeline.
+type Execution[B any] struct {
+	// Out receives the results, it is closed once the input is exhausted or the pipeline is stopped.
+	Out <-chan B
+	// DeadLetters receives failed items with the DeadLetter policy, nil otherwise. It has to be read
+	// together with Out, it is closed after Out.
+	DeadLetters <-chan *ItemError
+
+	x *execution
+}
+
+// Wait waits for all stages to stop. It returns the first *ItemError with the Abort policy,
+// the panic of a stage or the error of the context if it was done before the pipeline had finished.
+// It must be called after Out is drained, the done channel is closed or the context is done.
+// With the Drain mode Out has to be drained in any case.
+func (e *Execution[B]) Wait() error {
+	<-e.x.ended
+
+	e.x.mu.Lock()
+	defer e.x.mu.Unlock()
+	return e.x.err
+}
+
+// ExecuteErr is like Execute, but failures of ThenErr stages are handled according to policy.
+func (p Pipeline[A, B]) ExecuteErr(in <-chan A, done In, policy ErrorPolicy) *Execution[B] {
+	return p.execute(context.Background(), in, done, policy)
+}
+
+// ExecuteContext is like ExecuteErr, but the pipeline is stopped when ctx is done.
+// ThenContext stages receive a context that is also cancelled when the pipeline is aborted.
+func (p Pipeline[A, B]) ExecuteContext(ctx context.Context, in <-chan A, policy ErrorPolicy) *Execution[B] {
+	return p.execute(ctx, in, nil, policy)
+}
+
+func (p Pipeline[A, B]) execute(ctx context.Context, in <-chan A, done In, policy ErrorPolicy) *Execution[B] {
+	x := newExecution(ctx, done, policy, p.shutdown)
+	x.stages = p.stages
+	x.tracer = p.tracer
+	x.buffers = p.buffers
+	x.metrics = p.metrics.start(p.stages)
+	built := p.build(x, in)
+
+	out := make(chan B)
+	go func() {
+		defer x.end()
+		defer close(out)
+
+		for v := range built {
+			select {
+			case <-x.done:
+				return
+			case out <- v:
+			}
+		}
+	}()
+
+	return &Execution[B]{Out: out, DeadLetters: x.dead, x: x}
+}
+
+// ThenErr returns a pipeline applying f to the results of p. Items f fails or panics on are handled
+// by the ErrorPolicy of ExecuteErr, a panic is reported as *PanicError.
+func ThenErr[A, B, C any](p Pipeline[A, B], f func(B) (C, error)) Pipeline[A, C] {
+	stage := p.stages
+	return extend(p, func(x *execution, in <-chan B) <-chan C {
+		out := make(chan C)
+
+		x.wg.Add(1)
+		go func() {
+			defer x.wg.Done()
+			defer close(out)
+
+			for v := range in {
+				r, err := recovered(v, f)
+				if err != nil {
+					if !x.fail(&ItemError{Stage: stage, Item: v, Err: err}) {
+						return
+					}
+					continue
+				}
+
+				select {
+				case <-x.done:
+					return
+				case out <- r:
+				}
+			}
+		}()
+
+		return out
+	})
+}
@@ -0,0 +1,226 @@
+package hw06pipelineexecution
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+var errOdd = errors.New("odd value")
+
+func evenOnly(v int) (int, error) {
+	if v%2 != 0 {
+		return 0, errOdd
+	}
+	return v, nil
+}
+
+func TestExecuteErr(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	t.Run("no errors", func(t *testing.T) {
+		p := ThenErr(NewPipeline[string](), strconv.Atoi)
+		e := p.ExecuteErr(source("1", "2", "3"), nil, Abort)
+
+		result := make([]int, 0, 3)
+		for v := range e.Out {
+			result = append(result, v)
+		}
+
+		require.NoError(t, e.Wait())
+		require.Equal(t, []int{1, 2, 3}, result)
+		require.Nil(t, e.DeadLetters)
+	})
+
+	t.Run("abort on the first error", func(t *testing.T) {
+		// The input never ends, so only the abort can stop the pipeline.
+		in := make(chan int)
+		done := make(Bi)
+		defer close(done)
+		go func() {
+			for i := 0; ; i += 2 {
+				v := i
+				if i == 10 {
+					v = 11
+				}
+				select {
+				case <-done:
+					return
+				case in <- v:
+				}
+			}
+		}()
+
+		p := ThenErr(ThenErr(NewPipeline[int](), func(v int) (int, error) { return v, nil }), evenOnly)
+		e := p.ExecuteErr(in, done, Abort)
+
+		result := make([]int, 0, 5)
+		for v := range e.Out {
+			result = append(result, v)
+		}
+
+		err := e.Wait()
+		require.ErrorIs(t, err, errOdd)
+		var itemErr *ItemError
+		require.ErrorAs(t, err, &itemErr)
+		require.Equal(t, 1, itemErr.Stage)
+		require.Equal(t, 11, itemErr.Item)
+		// The items before the failed one may still be in flight when the pipeline is aborted.
+		require.LessOrEqual(t, len(result), 5)
+		require.Equal(t, []int{0, 2, 4, 6, 8}[:len(result)], result)
+	})
+
+	t.Run("execute aborts silently", func(t *testing.T) {
+		in := make(chan int, 4)
+		for _, v := range []int{2, 4, 5, 6} {
+			in <- v
+		}
+		close(in)
+
+		result := make([]int, 0, 2)
+		for v := range ThenErr(NewPipeline[int](), evenOnly).Execute(in, nil) {
+			result = append(result, v)
+		}
+		// Items that have passed the failed stage may be dropped by the abort too.
+		require.NotContains(t, result, 6)
+	})
+
+	t.Run("dead letters", func(t *testing.T) {
+		p := ThenErr(ThenErr(NewPipeline[int](), evenOnly), func(v int) (string, error) {
+			if v == 4 {
+				return "", fmt.Errorf("four")
+			}
+			return strconv.Itoa(v), nil
+		})
+		e := p.ExecuteErr(source(1, 2, 3, 4, 5, 6), nil, DeadLetter)
+
+		var result []string
+		var dead []*ItemError
+		for out, deadLetters := e.Out, e.DeadLetters; out != nil || deadLetters != nil; {
+			select {
+			case v, ok := <-out:
+				if !ok {
+					out = nil
+					continue
+				}
+				result = append(result, v)
+			case d, ok := <-deadLetters:
+				if !ok {
+					deadLetters = nil
+					continue
+				}
+				dead = append(dead, d)
+			}
+		}
+
+		require.NoError(t, e.Wait())
+		require.Equal(t, []string{"2", "6"}, result)
+		require.Len(t, dead, 4)
+		for _, d := range dead {
+			switch d.Item {
+			case 1, 3, 5:
+				require.Equal(t, 0, d.Stage)
+				require.ErrorIs(t, d, errOdd)
+			case 4:
+				require.Equal(t, 1, d.Stage)
+			default:
+				t.Errorf("unexpected dead letter %v", d)
+			}
+		}
+	})
+
+	t.Run("done stops all stages", func(t *testing.T) {
+		done := make(Bi)
+		in := make(chan int)
+		go func() {
+			defer close(in)
+			for i := 0; ; i += 2 {
+				select {
+				case <-done:
+					return
+				case in <- i:
+				}
+			}
+		}()
+
+		e := ThenErr(NewPipeline[int](), evenOnly).ExecuteErr(in, done, DeadLetter)
+		for i := 0; i < 3; i++ {
+			require.Equal(t, i*2, <-e.Out)
+		}
+		close(done)
+
+		for range e.Out {
+		}
+		require.NoError(t, e.Wait())
+	})
+}
+
+func TestItemStage(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	double := ItemStage(func(v interface{}) (interface{}, error) { return v.(int) * 2, nil })
+	half := ItemStage(func(v interface{}) (interface{}, error) {
+		if v.(int)%2 != 0 {
+			return nil, errOdd
+		}
+		return v.(int) / 2, nil
+	})
+
+	t.Run("values", func(t *testing.T) {
+		result := make([]interface{}, 0, 3)
+		for v := range ExecutePipeline(source[interface{}](1, 2, 3), nil, double, half) {
+			result = append(result, v)
+		}
+		require.Equal(t, []interface{}{1, 2, 3}, result)
+	})
+
+	t.Run("errors go to the pipeline", func(t *testing.T) {
+		e := NewUntypedPipeline(double, half, half).ExecuteErr(source[interface{}](2, 3), nil, DeadLetter)
+		result := make([]interface{}, 0, 1)
+		var dead []*ItemError
+		for e.Out != nil || e.DeadLetters != nil {
+			select {
+			case v, ok := <-e.Out:
+				if !ok {
+					e.Out = nil
+					continue
+				}
+				result = append(result, v)
+			case d, ok := <-e.DeadLetters:
+				if !ok {
+					e.DeadLetters = nil
+					continue
+				}
+				dead = append(dead, d)
+			}
+		}
+
+		require.NoError(t, e.Wait())
+		require.Equal(t, []interface{}{1}, result)
+		require.Len(t, dead, 1)
+		require.Equal(t, 2, dead[0].Stage)
+		require.Equal(t, 3, dead[0].Item)
+		require.ErrorIs(t, dead[0], errOdd)
+	})
+
+	t.Run("stopped with the pipeline", func(t *testing.T) {
+		in := make(Bi)
+		done := make(Bi)
+		out := ExecutePipeline(in, done, double)
+		in <- 1
+		close(done)
+		for range out {
+		}
+		close(in)
+	})
+
+	t.Run("outside of a pipeline", func(t *testing.T) {
+		in := make(Bi)
+		close(in)
+		require.Panics(t, func() { double(in) })
+	})
+}
@@ -0,0 +1,233 @@
+package hw06pipelineexecution
+
+import (
+	"sync"
+	"time"
+)
+
+// StageStats describes a stage of an instrumented pipeline.
+type StageStats struct {
+	// Stage is the index of the stage in the pipeline.
+	Stage int
+	// Taken and Emitted count the values the stage has received and sent.
+	Taken   uint64
+	Emitted uint64
+	// Depth is the number of values taken but not emitted yet. It is negative for stages
+	// emitting more values than they take.
+	Depth int64
+	// Throughput is the number of emitted values per second of the run.
+	Throughput float64
+	// AvgLatency is the average time a value spends in the stage, by Little's law: the time values
+	// have been held divided by the number of emitted ones. It is meaningful for stages emitting
+	// one value per value taken, use a Tracer for the others.
+	AvgLatency time.Duration
+	// Dropped is the number of values dropped by the buffer after the stage.
+	Dropped uint64
+	// RecvWait is the time the next stage has been waiting for this one. A stage with a large RecvWait
+	// and a small SendWait is the bottleneck.
+	RecvWait time.Duration
+	// SendWait is the time this stage has been waiting for the next one to take a value.
+	SendWait time.Duration
+}
+
+type stageMetrics struct {
+	taken, emitted uint64
+	dropped        uint64
+	changed        time.Time     // the time the depth has changed at
+	held           time.Duration // the sum of times of values in the stage
+	recvWait       time.Duration
+	sendWait       time.Duration
+}
+
+// hold accounts the time values have been in the stage till now.
+func (s *stageMetrics) hold(now time.Time) {
+	// Taking and emitting are recorded by different goroutines, so the depth may briefly go
+	// below zero for stages emitting values right away.
+	if depth := int64(s.taken) - int64(s.emitted); depth > 0 && !s.changed.IsZero() {
+		s.held += time.Duration(depth) * now.Sub(s.changed)
+	}
+	s.changed = now
+}
+
+// Metrics collects statistics of the stages of a pipeline, it is attached by Pipeline.WithMetrics.
+// The statistics describe the latest started run. Every run counts on its own, so runs sharing
+// the metrics, even of different pipelines, don't affect each other.
+type Metrics struct {
+	mu     sync.Mutex
+	latest *runMetrics
+}
+
+// runMetrics are the statistics of a single run.
+type runMetrics struct {
+	mu     sync.Mutex
+	began  time.Time
+	ended  time.Time
+	stages []stageMetrics
+}
+
+// NewMetrics creates empty metrics to attach to a pipeline.
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+// Snapshot returns the current statistics of every stage.
+func (m *Metrics) Snapshot() []StageStats {
+	m.mu.Lock()
+	r := m.latest
+	m.mu.Unlock()
+	if r == nil {
+		return []StageStats{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	end := r.ended
+	if end.IsZero() {
+		end = time.Now()
+	}
+	elapsed := end.Sub(r.began).Seconds()
+
+	stats := make([]StageStats, len(r.stages))
+	for i, s := range r.stages {
+		stats[i] = StageStats{
+			Stage:    i,
+			Taken:    s.taken,
+			Emitted:  s.emitted,
+			Depth:    int64(s.taken) - int64(s.emitted),
+			Dropped:  s.dropped,
+			RecvWait: s.recvWait,
+			SendWait: s.sendWait,
+		}
+		if elapsed > 0 {
+			stats[i].Throughput = float64(s.emitted) / elapsed
+		}
+		if s.emitted > 0 {
+			stats[i].AvgLatency = s.held / time.Duration(s.emitted)
+		}
+	}
+
+	return stats
+}
+
+// start publishes the statistics of a new run, it returns nil if m is nil.
+func (m *Metrics) start(stages int) *runMetrics {
+	if m == nil {
+		return nil
+	}
+
+	r := &runMetrics{began: time.Now(), stages: make([]stageMetrics, stages)}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latest = r
+	return r
+}
+
+func (r *runMetrics) stop() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ended = time.Now()
+}
+
+func (r *runMetrics) taken(stage int, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := &r.stages[stage]
+	s.hold(at)
+	s.taken++
+}
+
+func (r *runMetrics) emitted(stage int, at time.Time, waited time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := &r.stages[stage]
+	s.hold(at)
+	s.emitted++
+	s.recvWait += waited
+}
+
+func (r *runMetrics) drop(stage int) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stages[stage].dropped++
+}
+
+func (r *runMetrics) sendWaited(stage int, waited time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stages[stage].sendWait += waited
+}
+
+// WithMetrics returns the pipeline recording statistics of its stages to m.
+// Stages added after that are instrumented too.
+func (p Pipeline[A, B]) WithMetrics(m *Metrics) Pipeline[A, B] {
+	p.metrics = m
+	return p
+}
+
+// probe records the passage of values through the stageProcess after a stage.
+type probe struct {
+	x     *execution
+	stage int
+}
+
+// probe returns nil if the pipeline isn't instrumented.
+func (x *execution) probe(stage int) *probe {
+	if x.metrics == nil && x.tracer == nil {
+		return nil
+	}
+	return &probe{x: x, stage: stage}
+}
+
+func (p *probe) now() time.Time {
+	if p == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// received is called when v is emitted by the stage, waiting has started at from.
+func (p *probe) received(v interface{}, from time.Time) {
+	if p == nil || p.stage < 0 {
+		return
+	}
+
+	now := time.Now()
+	if p.x.metrics != nil {
+		p.x.metrics.emitted(p.stage, now, now.Sub(from))
+	}
+	if id, ok := traceID(p.x.tracer, v); ok {
+		p.x.tracer.emitted(id, p.stage, now)
+	}
+}
+
+// sent is called when v is taken by the next stage, waiting has started at from.
+func (p *probe) sent(v interface{}, from time.Time) {
+	if p == nil {
+		return
+	}
+
+	now := time.Now()
+	next := p.stage + 1
+	if p.x.metrics != nil {
+		if p.stage >= 0 {
+			p.x.metrics.sendWaited(p.stage, now.Sub(from))
+		}
+		if next < p.x.stages {
+			p.x.metrics.taken(next, now)
+		}
+	}
+	if id, ok := traceID(p.x.tracer, v); ok && next < p.x.stages {
+		p.x.tracer.taken(id, next, now)
+	}
+}
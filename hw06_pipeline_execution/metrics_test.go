@@ -0,0 +1,120 @@
+package hw06pipelineexecution
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMetrics(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	t.Run("bottleneck", func(t *testing.T) {
+		m := NewMetrics()
+		fast := typedStage(func(v int) int { return v })
+		slow := typedStage(func(v int) int {
+			time.Sleep(20 * time.Millisecond)
+			return v
+		})
+		p := Then(Then(Then(NewPipeline[int]().WithMetrics(m), fast), slow), fast)
+
+		for range p.Execute(source(1, 2, 3, 4, 5), nil) {
+		}
+
+		stats := m.Snapshot()
+		require.Len(t, stats, 3)
+		for i, s := range stats {
+			require.Equal(t, i, s.Stage)
+			require.Equal(t, uint64(5), s.Taken)
+			require.Equal(t, uint64(5), s.Emitted)
+			require.Zero(t, s.Depth)
+			require.Greater(t, s.Throughput, 0.0)
+		}
+
+		require.GreaterOrEqual(t, stats[1].AvgLatency, 15*time.Millisecond)
+		// The stage before the slow one waits to send, the one after it waits to receive.
+		require.Greater(t, stats[0].SendWait, stats[0].RecvWait)
+		require.Greater(t, stats[1].RecvWait, stats[1].SendWait)
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		m := NewMetrics()
+		double := func(in <-chan int) <-chan int {
+			out := make(chan int)
+			go func() {
+				defer close(out)
+				for v := range in {
+					out <- v
+					out <- v
+				}
+			}()
+			return out
+		}
+		p := Then(NewPipeline[int](), TypedStage[int, int](double)).WithMetrics(m)
+
+		result := make([]int, 0, 4)
+		for v := range p.Execute(source(1, 2), nil) {
+			result = append(result, v)
+		}
+
+		require.Equal(t, []int{1, 1, 2, 2}, result)
+		s := m.Snapshot()[0]
+		require.Equal(t, uint64(2), s.Taken)
+		require.Equal(t, uint64(4), s.Emitted)
+		require.Equal(t, int64(-2), s.Depth)
+	})
+
+	t.Run("latest run", func(t *testing.T) {
+		m := NewMetrics()
+		p := NewUntypedPipeline(Stage(typedStage(func(v interface{}) interface{} { return v }))).WithMetrics(m)
+
+		for i := 0; i < 2; i++ {
+			in := make(Bi)
+			go func() {
+				defer close(in)
+				in <- 1
+			}()
+			for range p.Execute(in, nil) {
+			}
+		}
+
+		require.Equal(t, uint64(1), m.Snapshot()[0].Emitted)
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		m := NewMetrics()
+		same := typedStage(func(v int) int { return v })
+		short := Then(NewPipeline[int](), same).WithMetrics(m)
+		long := Then(Then(Then(NewPipeline[int](), same), same), same).WithMetrics(m)
+
+		// Runs of pipelines with different numbers of stages overlap.
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			for _, p := range []Pipeline[int, int]{short, long, short} {
+				p := p
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for range p.Execute(source(1, 2, 3), nil) {
+					}
+				}()
+			}
+		}
+		wg.Wait()
+
+		for range long.Execute(source(1, 2), nil) {
+		}
+		stats := m.Snapshot()
+		require.Len(t, stats, 3)
+		for _, s := range stats {
+			require.Equal(t, uint64(2), s.Emitted)
+		}
+	})
+
+	t.Run("without metrics", func(t *testing.T) {
+		require.Empty(t, NewMetrics().Snapshot())
+	})
+}
@@ -0,0 +1,45 @@
+package hw06pipelineexecution
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is a panic of a stage turned into an error of the pipeline. Panics are recovered when
+// stages are started and in the functions of ItemStage, ThenErr and ThenParallel, which run
+// in goroutines of the pipeline. Go can't recover a panic of a goroutine from another one,
+// so other stages running their own goroutines have to recover themselves.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// catch calls f and returns its panic as *PanicError.
+func catch(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	f()
+	return nil
+}
+
+// recovered is f(v) with a panic returned as *PanicError.
+func recovered[A, B any](v A, f func(A) (B, error)) (r B, err error) {
+	if perr := catch(func() { r, err = f(v) }); perr != nil {
+		return r, perr
+	}
+	return r, err
+}
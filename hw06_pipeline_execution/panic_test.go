@@ -0,0 +1,142 @@
+package hw06pipelineexecution
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// panicOn returns a function panicking with value on item bad.
+func panicOn(bad int, value interface{}) func(int) int {
+	return func(v int) int {
+		if v == bad {
+			panic(value)
+		}
+		return v * 10
+	}
+}
+
+func TestPanics(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	t.Run("item with abort", func(t *testing.T) {
+		f := panicOn(3, "boom")
+		p := ThenErr(NewPipeline[int](), func(v int) (int, error) { return f(v), nil })
+
+		e := p.ExecuteErr(source(1, 2, 3), nil, Abort)
+		result := make([]int, 0, 2)
+		for v := range e.Out {
+			result = append(result, v)
+		}
+
+		err := e.Wait()
+		var itemErr *ItemError
+		require.True(t, errors.As(err, &itemErr))
+		require.Equal(t, 0, itemErr.Stage)
+		require.Equal(t, 3, itemErr.Item)
+
+		var panicErr *PanicError
+		require.True(t, errors.As(err, &panicErr))
+		require.Equal(t, "boom", panicErr.Value)
+		require.NotEmpty(t, panicErr.Stack)
+		// Values in flight are discarded on abort.
+		require.LessOrEqual(t, len(result), 2)
+		require.Equal(t, []int{10, 20}[:len(result)], result)
+	})
+
+	t.Run("parallel item with dead letters", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		p := ThenParallel(NewPipeline[int](), 3, true, panicOn(2, errBoom))
+		p = ThenParallel(p, 3, false, func(v int) int { return v + 1 })
+
+		e := p.ExecuteErr(source(1, 2, 3, 4), nil, DeadLetter)
+		result := make([]int, 0, 3)
+		var dead []*ItemError
+		for e.Out != nil || e.DeadLetters != nil {
+			select {
+			case v, ok := <-e.Out:
+				if !ok {
+					e.Out = nil
+					continue
+				}
+				result = append(result, v)
+			case d, ok := <-e.DeadLetters:
+				if !ok {
+					e.DeadLetters = nil
+					continue
+				}
+				dead = append(dead, d)
+			}
+		}
+
+		require.NoError(t, e.Wait())
+		require.ElementsMatch(t, []int{11, 31, 41}, result)
+		require.Len(t, dead, 1)
+		require.Equal(t, 0, dead[0].Stage)
+		require.Equal(t, 2, dead[0].Item)
+		require.ErrorIs(t, dead[0], errBoom)
+	})
+
+	t.Run("stage start", func(t *testing.T) {
+		p := ThenErr(NewPipeline[int](), func(v int) (int, error) { return v, nil })
+		p = Then(p, func(in <-chan int) <-chan int {
+			panic("no goroutine today")
+		})
+
+		e := p.ExecuteErr(source[int](), nil, Abort)
+		for range e.Out {
+		}
+
+		err := e.Wait()
+		var panicErr *PanicError
+		require.True(t, errors.As(err, &panicErr))
+		require.Equal(t, "no goroutine today", panicErr.Value)
+		require.EqualError(t, err, "stage 1: panic: no goroutine today")
+	})
+}
+
+func TestItemStagePanics(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	// The type assertion panics in the goroutine of the stage on the string.
+	half := ItemStage(func(v interface{}) (interface{}, error) { return v.(int) / 2, nil })
+	same := ItemStage(func(v interface{}) (interface{}, error) { return v, nil })
+
+	t.Run("panic in the goroutine of a stage", func(t *testing.T) {
+		e := NewUntypedPipeline(same, half).ExecuteErr(source[interface{}](2, "four"), nil, Abort)
+		for range e.Out {
+		}
+
+		err := e.Wait()
+		var itemErr *ItemError
+		require.True(t, errors.As(err, &itemErr))
+		require.Equal(t, 1, itemErr.Stage)
+		require.Equal(t, "four", itemErr.Item)
+		var panicErr *PanicError
+		require.True(t, errors.As(err, &panicErr))
+	})
+
+	t.Run("ExecutePipeline stops on a panic", func(t *testing.T) {
+		result := make([]interface{}, 0, 1)
+		for v := range ExecutePipeline(source[interface{}]("one"), nil, half) {
+			result = append(result, v)
+		}
+		require.Empty(t, result)
+	})
+
+	t.Run("run by another stage", func(t *testing.T) {
+		in := make(Bi)
+		close(in)
+		wrapped := func(in In) Out { return half(in) }
+		e := NewUntypedPipeline(wrapped).ExecuteErr(in, nil, Abort)
+		for range e.Out {
+		}
+
+		err := e.Wait()
+		var panicErr *PanicError
+		require.True(t, errors.As(err, &panicErr))
+		require.Contains(t, err.Error(), "ItemStage is run outside of NewUntypedPipeline")
+	})
+}
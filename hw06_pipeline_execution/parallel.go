@@ -0,0 +1,144 @@
+package hw06pipelineexecution
+
+import "sync"
+
+// sequenced is a value tagged with its position in the input of a parallel stage.
+type sequenced[T any] struct {
+	seq  int
+	v    T
+	skip bool // there is no result for the value
+}
+
+// Parallel returns a stage applying f to values in the given number of goroutines. If ordered is true,
+// values are emitted in the input order, otherwise as soon as they are ready. Closing done stops the stage.
+func Parallel[A, B any](done In, workers int, ordered bool, f func(A) B) TypedStage[A, B] {
+	return func(in <-chan A) <-chan B {
+		return parallel(done, in, workers, ordered, func(v A) (B, bool) {
+			return f(v), true
+		})
+	}
+}
+
+// ThenParallel is like Then with a Parallel stage, the stage stops when the done of Execute is closed.
+// A panic of f is a *PanicError of the item, it is handled by the ErrorPolicy of ExecuteErr.
+func ThenParallel[A, B, C any](p Pipeline[A, B], workers int, ordered bool, f func(B) C) Pipeline[A, C] {
+	stage := p.stages
+	return extend(p, func(x *execution, in <-chan B) <-chan C {
+		return parallel(x.done, in, workers, ordered, func(v B) (C, bool) {
+			r, err := recovered(v, func(v B) (C, error) {
+				return f(v), nil
+			})
+			if err != nil {
+				// The item is dropped either way, the pipeline is stopped on abort.
+				x.fail(&ItemError{Stage: stage, Item: v, Err: err})
+				return r, false
+			}
+			return r, true
+		})
+	})
+}
+
+// parallel applies f to values, results f returns false for are dropped.
+func parallel[A, B any](done In, in <-chan A, workers int, ordered bool, f func(A) (B, bool)) <-chan B {
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan sequenced[A])
+	results := make(chan sequenced[B])
+	out := make(chan B)
+	// Slots limit the number of values between reading and emitting,
+	// so a single slow value can't make the reorder buffer grow without bound.
+	slots := make(chan struct{}, 2*workers)
+
+	go func() {
+		defer close(jobs)
+
+		for seq := 0; ; seq++ {
+			select {
+			case <-done:
+				return
+			case slots <- struct{}{}:
+			}
+
+			var v A
+			var ok bool
+			select {
+			case <-done:
+				return
+			case v, ok = <-in:
+				if !ok {
+					return
+				}
+			}
+
+			select {
+			case <-done:
+				return
+			case jobs <- sequenced[A]{seq: seq, v: v}:
+			}
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for j := range jobs {
+				v, ok := f(j.v)
+				select {
+				case <-done:
+					return
+				case results <- sequenced[B]{seq: j.seq, v: v, skip: !ok}:
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	go func() {
+		defer close(out)
+
+		emit := func(r sequenced[B]) bool {
+			if r.skip {
+				<-slots
+				return true
+			}
+
+			select {
+			case <-done:
+				return false
+			case out <- r.v:
+				<-slots
+				return true
+			}
+		}
+
+		pending := make(map[int]sequenced[B]) // results waiting for the preceding ones in ordered mode
+		next := 0
+		for r := range results {
+			if !ordered {
+				if !emit(r) {
+					return
+				}
+				continue
+			}
+
+			pending[r.seq] = r
+			for p, ok := pending[next]; ok; p, ok = pending[next] {
+				delete(pending, next)
+				next++
+				if !emit(p) {
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,135 @@
+package hw06pipelineexecution
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// barrier blocks callers of wait until n of them have arrived.
+type barrier struct {
+	mu      sync.Mutex
+	arrived int
+	n       int
+	all     chan struct{}
+}
+
+func newBarrier(n int) *barrier {
+	return &barrier{n: n, all: make(chan struct{})}
+}
+
+func (b *barrier) wait() {
+	b.mu.Lock()
+	b.arrived++
+	if b.arrived == b.n {
+		close(b.all)
+	}
+	b.mu.Unlock()
+	<-b.all
+}
+
+func TestParallel(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	t.Run("workers run concurrently", func(t *testing.T) {
+		workers := 4
+		b := newBarrier(workers)
+		// Without all workers running at once the first values would wait for the barrier forever.
+		p := ThenParallel(NewPipeline[int](), workers, false, func(v int) int {
+			if v < workers {
+				b.wait()
+			}
+			return v
+		})
+
+		result := make([]int, 0, 10)
+		for v := range p.Execute(source(0, 1, 2, 3, 4, 5, 6, 7, 8, 9), nil) {
+			result = append(result, v)
+		}
+
+		sort.Ints(result)
+		require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, result)
+	})
+
+	t.Run("ordered", func(t *testing.T) {
+		values := make([]int, 100)
+		for i := range values {
+			values[i] = i
+		}
+
+		// Every value waits for the next one, so they are completed in the reverse order within a group.
+		gates := make([]chan struct{}, len(values)+1)
+		for i := range gates {
+			gates[i] = make(chan struct{})
+		}
+		f := func(v int) string {
+			if v%4 != 3 {
+				<-gates[v+1]
+			}
+			close(gates[v])
+			return strconv.Itoa(v)
+		}
+
+		result := make([]string, 0, len(values))
+		for s := range ThenParallel(NewPipeline[int](), 4, true, f).Execute(source(values...), nil) {
+			result = append(result, s)
+		}
+
+		require.Len(t, result, len(values))
+		for i, s := range result {
+			require.Equal(t, strconv.Itoa(i), s)
+		}
+	})
+
+	t.Run("untyped stage", func(t *testing.T) {
+		in := make(Bi)
+		go func() {
+			defer close(in)
+			for i := 1; i <= 5; i++ {
+				in <- i
+			}
+		}()
+
+		square := Parallel(nil, 3, true, func(v interface{}) interface{} { return v.(int) * v.(int) })
+		result := make([]int, 0, 5)
+		for v := range ExecutePipeline(in, nil, Stage(square)) {
+			result = append(result, v.(int))
+		}
+
+		require.Equal(t, []int{1, 4, 9, 16, 25}, result)
+	})
+
+	t.Run("done", func(t *testing.T) {
+		done := make(Bi)
+		in := make(chan int)
+		go func() {
+			defer close(in)
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				case in <- i:
+				}
+			}
+		}()
+
+		p := ThenParallel(NewPipeline[int](), 4, true, func(v int) int {
+			time.Sleep(time.Millisecond)
+			return v
+		})
+		out := p.Execute(in, done)
+
+		for i := 0; i < 10; i++ {
+			require.Equal(t, i, <-out)
+		}
+		close(done)
+		// The rest is dropped, the output has to be closed.
+		for range out {
+		}
+	})
+}
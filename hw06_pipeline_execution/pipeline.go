@@ -1,5 +1,7 @@
 package hw06pipelineexecution
 
+import "reflect"
+
 type (
 	In  = <-chan interface{}
 	Out = In
@@ -8,34 +10,97 @@ type (
 
 type Stage func(in In) (out Out)
 
+// ItemStage returns a Stage applying f to every value. The pipeline runs it as a ThenErr stage, so it stops
+// with the pipeline and errors of f are handled by the ErrorPolicy. The stage has to be passed
+// to ExecutePipeline or NewUntypedPipeline as is, called in any other way, e.g. by another stage, it panics,
+// as the errors of f would have nowhere to go.
+func ItemStage(f func(v interface{}) (interface{}, error)) Stage {
+	return itemStage(f).stage
+}
+
+type itemStage func(v interface{}) (interface{}, error)
+
+var (
+	// itemProbe is passed to the stages made by ItemStage to get their functions.
+	itemProbe     = make(Bi)
+	itemStageCode = reflect.ValueOf(itemStage(nil).stage).Pointer()
+)
+
+func (f itemStage) stage(in In) Out {
+	if in != In(itemProbe) {
+		panic("hw06pipelineexecution: ItemStage is run outside of NewUntypedPipeline")
+	}
+
+	out := make(Bi, 1)
+	out <- f
+	return out
+}
+
+// itemFunc returns the function of a stage made by ItemStage.
+func itemFunc(stage Stage) (func(v interface{}) (interface{}, error), bool) {
+	if reflect.ValueOf(stage).Pointer() != itemStageCode {
+		return nil, false
+	}
+
+	f, _ := (<-stage(itemProbe)).(itemStage)
+	return f, true
+}
+
+// ExecutePipeline is the untyped version of Pipeline.Execute, all stages pass interface{} values.
+// A failure of an ItemStage stops the pipeline, use NewUntypedPipeline and ExecuteErr to get it.
 func ExecutePipeline(in In, done In, stages ...Stage) Out {
-	out := stageProcess(done, in)
+	return NewUntypedPipeline(stages...).Execute(in, done)
+}
+
+// NewUntypedPipeline returns a pipeline of untyped stages, e.g. to instrument the stages of ExecutePipeline.
+func NewUntypedPipeline(stages ...Stage) Pipeline[interface{}, interface{}] {
+	p := NewPipeline[interface{}]()
 	for _, stage := range stages {
-		s := stage
-		out = stageProcess(done, s(out))
+		if f, ok := itemFunc(stage); ok {
+			p = ThenErr(p, f)
+			continue
+		}
+		p = Then(p, TypedStage[interface{}, interface{}](stage))
 	}
 
-	return out
+	return p
 }
 
-func stageProcess(done In, in In) In {
-	out := make(Bi)
+// stageProcess passes the output of the stage at index stage, -1 for the input of the pipeline,
+// to the next one until the output is closed or the pipeline is stopped.
+func stageProcess[T any](x *execution, stage int, in <-chan T) <-chan T {
+	if b := x.buffers[stage]; b.Size > 0 {
+		return bufferProcess(x, stage, b, in)
+	}
+
+	stop := x.done
+	if stage < 0 {
+		// The input is read until the pipeline is drained, values taken before are passed on.
+		stop = x.draining
+	}
+
+	out := make(chan T)
 	go func() {
 		defer close(out)
 
+		p := x.probe(stage)
 		for {
+			waitFrom := p.now()
 			select {
-			case <-done:
+			case <-stop:
 				return
 			case v, ok := <-in:
 				if !ok {
 					return
 				}
+				p.received(v, waitFrom)
 
+				waitFrom = p.now()
 				select {
-				case <-done:
+				case <-x.done:
 					return
 				case out <- v:
+					p.sent(v, waitFrom)
 				}
 			}
 		}
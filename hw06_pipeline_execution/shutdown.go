@@ -0,0 +1,41 @@
+package hw06pipelineexecution
+
+import (
+	"context"
+	"time"
+)
+
+// ShutdownMode tells a pipeline what to do when its done channel is closed or its context is done.
+type ShutdownMode int
+
+const (
+	// HardAbort stops all stages at once, values in flight are discarded.
+	HardAbort ShutdownMode = iota
+	// Drain stops reading the input, values already taken are processed by all stages before the output
+	// is closed. Stages must finish once their input is closed; errors with the Abort policy still stop
+	// the pipeline at once.
+	Drain
+)
+
+// WithShutdown returns the pipeline stopping in the given mode.
+func (p Pipeline[A, B]) WithShutdown(mode ShutdownMode) Pipeline[A, B] {
+	p.shutdown = mode
+	return p
+}
+
+// detachedContext keeps the values of a context, but is never done.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
@@ -0,0 +1,140 @@
+package hw06pipelineexecution
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// counting sends 0, 1, 2... to in until stop is closed, sent returns the number of values taken.
+type counting struct {
+	in   chan int
+	stop chan struct{}
+	wg   sync.WaitGroup
+	sent int
+}
+
+func newCounting() *counting {
+	c := &counting{in: make(chan int), stop: make(chan struct{})}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case c.in <- c.sent:
+				c.sent++
+			}
+		}
+	}()
+	return c
+}
+
+func (c *counting) stopped() int {
+	close(c.stop)
+	c.wg.Wait()
+	return c.sent
+}
+
+func TestDrain(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	slow := func(v int) (int, error) {
+		time.Sleep(time.Millisecond)
+		return v, nil
+	}
+	drained := func(p Pipeline[int, int]) Pipeline[int, int] {
+		p = ThenErr(p, slow)
+		p = ThenParallel(p, 4, true, func(v int) int { return v })
+		return ThenErr(p, slow).WithShutdown(Drain)
+	}
+
+	pipelines := map[string]Pipeline[int, int]{
+		"unbuffered": drained(NewPipeline[int]()),
+		"buffered":   drained(NewPipeline[int]().WithBuffer(Buffer{Size: 5})),
+	}
+	for name, p := range pipelines {
+		p := p
+		t.Run(name, func(t *testing.T) {
+			source := newCounting()
+			done := make(Bi)
+			e := p.ExecuteErr(source.in, done, Abort)
+
+			result := make([]int, 0, 10)
+			for v := range e.Out {
+				result = append(result, v)
+				if len(result) == 3 {
+					close(done)
+				}
+			}
+			require.NoError(t, e.Wait())
+
+			// Every value the pipeline has taken comes out.
+			sent := source.stopped()
+			require.Len(t, result, sent)
+			for i, v := range result {
+				require.Equal(t, i, v)
+			}
+		})
+	}
+
+	t.Run("context", func(t *testing.T) {
+		source := newCounting()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var stageCtx context.Context
+		p := ThenContext(NewPipeline[int](), func(c context.Context, in <-chan int) <-chan int {
+			stageCtx = c
+			out := make(chan int)
+			go func() {
+				defer close(out)
+				for v := range in {
+					if c.Err() != nil {
+						// The stage is stopped before the values in flight are processed.
+						return
+					}
+					out <- v
+				}
+			}()
+			return out
+		})
+		p = ThenErr(p, slow).WithShutdown(Drain)
+
+		e := p.ExecuteContext(ctx, source.in, Abort)
+		result := make([]int, 0, 10)
+		for v := range e.Out {
+			result = append(result, v)
+			if len(result) == 3 {
+				cancel()
+			}
+		}
+
+		require.ErrorIs(t, e.Wait(), context.Canceled)
+		require.Len(t, result, source.stopped())
+		require.ErrorIs(t, stageCtx.Err(), context.Canceled)
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		stop := errors.New("stop")
+		p := ThenErr(NewPipeline[int](), func(v int) (int, error) {
+			if v == 2 {
+				return 0, stop
+			}
+			return v, nil
+		}).WithShutdown(Drain)
+
+		source := newCounting()
+		e := p.ExecuteErr(source.in, nil, Abort)
+		for range e.Out {
+		}
+		require.ErrorIs(t, e.Wait(), stop)
+		source.stopped()
+	})
+}
@@ -0,0 +1,123 @@
+package hw06pipelineexecution
+
+import (
+	"sync"
+	"time"
+)
+
+// Traced values carry an ID a Tracer uses to follow them through the stages. A stage changing
+// the values should keep the ID for the trace to go on.
+type Traced interface {
+	TraceID() string
+}
+
+// Hop is the passage of a traced value through a stage.
+type Hop struct {
+	Stage int
+	// Taken is zero if the stage has made the value itself.
+	Taken time.Time
+	// Emitted is zero while the value is in the stage.
+	Emitted time.Time
+}
+
+// Tracer records the path of Traced values through a pipeline, it is attached by Pipeline.WithTracer.
+type Tracer struct {
+	capacity int
+
+	mu     sync.Mutex
+	traces map[string][]Hop
+	order  []string // IDs from the oldest one
+}
+
+// NewTracer creates a tracer keeping the paths of up to capacity most recent values.
+func NewTracer(capacity int) *Tracer {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &Tracer{
+		capacity: capacity,
+		traces:   make(map[string][]Hop, capacity),
+	}
+}
+
+// Trace returns the hops of the value with id in the order of stages, nil if it is unknown.
+func (t *Tracer) Trace(id string) []Hop {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	hops := t.traces[id]
+	if hops == nil {
+		return nil
+	}
+	return append([]Hop(nil), hops...)
+}
+
+// hops must be called with t.mu held.
+func (t *Tracer) hops(id string) []Hop {
+	hops, ok := t.traces[id]
+	if !ok {
+		if len(t.order) == t.capacity {
+			delete(t.traces, t.order[0])
+			t.order = t.order[1:]
+		}
+		t.order = append(t.order, id)
+	}
+	return hops
+}
+
+func (t *Tracer) taken(id string, stage int, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Taking and emitting are recorded by different goroutines, the emission and even the next
+	// stage taking the value may come first.
+	hops := t.hops(id)
+	if i := lastHop(hops, stage); i >= 0 && hops[i].Taken.IsZero() {
+		if at.After(hops[i].Emitted) {
+			at = hops[i].Emitted
+		}
+		hops[i].Taken = at
+		return
+	}
+	t.traces[id] = append(hops, Hop{Stage: stage, Taken: at})
+}
+
+func (t *Tracer) emitted(id string, stage int, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	hops := t.hops(id)
+	if i := lastHop(hops, stage); i >= 0 && hops[i].Emitted.IsZero() {
+		hops[i].Emitted = at
+		return
+	}
+	t.traces[id] = append(hops, Hop{Stage: stage, Emitted: at})
+}
+
+// lastHop returns the index of the latest hop through stage, -1 if there is none.
+func lastHop(hops []Hop, stage int) int {
+	for i := len(hops) - 1; i >= 0; i-- {
+		if hops[i].Stage == stage {
+			return i
+		}
+	}
+	return -1
+}
+
+// WithTracer returns the pipeline recording the paths of Traced values to t.
+func (p Pipeline[A, B]) WithTracer(t *Tracer) Pipeline[A, B] {
+	p.tracer = t
+	return p
+}
+
+func traceID(t *Tracer, v interface{}) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	traced, ok := v.(Traced)
+	if !ok {
+		return "", false
+	}
+	return traced.TraceID(), true
+}
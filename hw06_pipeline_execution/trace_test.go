@@ -0,0 +1,71 @@
+package hw06pipelineexecution
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+type item struct {
+	id string
+	v  int
+}
+
+func (i item) TraceID() string {
+	return i.id
+}
+
+func TestTracer(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	t.Run("path", func(t *testing.T) {
+		tracer := NewTracer(10)
+		inc := typedStage(func(i item) item { return item{id: i.id, v: i.v + 1} })
+		p := Then(Then(NewPipeline[item](), inc), inc).WithTracer(tracer)
+
+		result := make([]int, 0, 3)
+		for i := range p.Execute(source(item{"a", 1}, item{"b", 2}, item{"c", 3}), nil) {
+			result = append(result, i.v)
+		}
+		require.Equal(t, []int{3, 4, 5}, result)
+
+		hops := tracer.Trace("b")
+		require.Len(t, hops, 2)
+		for stage, hop := range hops {
+			require.Equal(t, stage, hop.Stage)
+			require.False(t, hop.Taken.IsZero())
+			require.False(t, hop.Emitted.Before(hop.Taken))
+		}
+		require.False(t, hops[1].Taken.Before(hops[0].Emitted))
+
+		require.Nil(t, tracer.Trace("unknown"))
+	})
+
+	t.Run("values made by a stage", func(t *testing.T) {
+		tracer := NewTracer(10)
+		toItem := typedStage(func(v int) item { return item{id: strconv.Itoa(v), v: v} })
+		p := Then(NewPipeline[int](), toItem).WithTracer(tracer)
+
+		for range p.Execute(source(1), nil) {
+		}
+
+		hops := tracer.Trace("1")
+		require.Len(t, hops, 1)
+		require.True(t, hops[0].Taken.IsZero())
+		require.False(t, hops[0].Emitted.IsZero())
+	})
+
+	t.Run("capacity", func(t *testing.T) {
+		tracer := NewTracer(2)
+		p := Then(NewPipeline[item](), typedStage(func(i item) item { return i })).WithTracer(tracer)
+
+		for range p.Execute(source(item{id: "a"}, item{id: "b"}, item{id: "c"}), nil) {
+		}
+
+		require.Nil(t, tracer.Trace("a"))
+		require.Len(t, tracer.Trace("b"), 1)
+		require.Len(t, tracer.Trace("c"), 1)
+	})
+}
@@ -0,0 +1,64 @@
+package hw06pipelineexecution
+
+import "fmt"
+
+// TypedStage is a Stage with the types of its input and output checked by the compiler.
+type TypedStage[A, B any] func(in <-chan A) (out <-chan B)
+
+// Pipeline is a chain of stages turning values of type A into values of type B.
+// It is created by NewPipeline and extended by Then, as Go methods can't have type parameters of their own.
+type Pipeline[A, B any] struct {
+	stages   int
+	metrics  *Metrics
+	tracer   *Tracer
+	buffers  map[int]Buffer // by the index of the stage before a buffer, it is copied on change
+	shutdown ShutdownMode
+	build    func(x *execution, in <-chan A) <-chan B
+}
+
+// NewPipeline returns an empty pipeline passing values of type A through.
+func NewPipeline[A any]() Pipeline[A, A] {
+	return Pipeline[A, A]{
+		build: func(x *execution, in <-chan A) <-chan A {
+			return stageProcess(x, -1, in)
+		},
+	}
+}
+
+// Then returns a pipeline running stage after the stages of p.
+func Then[A, B, C any](p Pipeline[A, B], stage TypedStage[B, C]) Pipeline[A, C] {
+	return extend(p, func(x *execution, in <-chan B) <-chan C {
+		return stage(in)
+	})
+}
+
+// extend returns p with one more stage. The output of the stage is passed through stageProcess.
+func extend[A, B, C any](p Pipeline[A, B], stage func(x *execution, in <-chan B) <-chan C) Pipeline[A, C] {
+	index := p.stages
+	return Pipeline[A, C]{
+		stages:   p.stages + 1,
+		metrics:  p.metrics,
+		tracer:   p.tracer,
+		buffers:  p.buffers,
+		shutdown: p.shutdown,
+		build: func(x *execution, in <-chan A) <-chan C {
+			built := p.build(x, in)
+
+			var out <-chan C
+			if err := catch(func() { out = stage(x, built) }); err != nil {
+				x.abort(fmt.Errorf("stage %d: %w", index, err))
+				closed := make(chan C)
+				close(closed)
+				out = closed
+			}
+
+			return stageProcess(x, index, out)
+		},
+	}
+}
+
+// Execute starts the stages on in. The output is closed once in is exhausted or done is closed.
+// An error of a ThenErr stage stops the pipeline, use ExecuteErr to get it.
+func (p Pipeline[A, B]) Execute(in <-chan A, done In) <-chan B {
+	return p.ExecuteErr(in, done, Abort).Out
+}
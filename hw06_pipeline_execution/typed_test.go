@@ -0,0 +1,88 @@
+package hw06pipelineexecution
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// typedStage makes a stage applying f to every value.
+func typedStage[A, B any](f func(A) B) TypedStage[A, B] {
+	return func(in <-chan A) <-chan B {
+		out := make(chan B)
+		go func() {
+			defer close(out)
+			for v := range in {
+				out <- f(v)
+			}
+		}()
+		return out
+	}
+}
+
+// source sends values to a new channel and closes it.
+func source[T any](values ...T) <-chan T {
+	in := make(chan T)
+	go func() {
+		defer close(in)
+		for _, v := range values {
+			in <- v
+		}
+	}()
+	return in
+}
+
+func TestTypedPipeline(t *testing.T) {
+	t.Run("stages change types", func(t *testing.T) {
+		p := Then(
+			Then(
+				Then(NewPipeline[int](), typedStage(func(v int) int { return v * 2 })),
+				typedStage(func(v int) int { return v + 100 }),
+			),
+			typedStage(strconv.Itoa),
+		)
+
+		result := make([]string, 0, 5)
+		for s := range p.Execute(source(1, 2, 3, 4, 5), nil) {
+			result = append(result, s)
+		}
+
+		require.Equal(t, []string{"102", "104", "106", "108", "110"}, result)
+	})
+
+	t.Run("empty pipeline", func(t *testing.T) {
+		result := make([]string, 0, 2)
+		for s := range NewPipeline[string]().Execute(source("a", "b"), nil) {
+			result = append(result, s)
+		}
+
+		require.Equal(t, []string{"a", "b"}, result)
+	})
+
+	t.Run("done", func(t *testing.T) {
+		done := make(Bi)
+		close(done)
+
+		p := Then(NewPipeline[int](), typedStage(strconv.Itoa))
+		in := make(chan int)
+		result := make([]string, 0)
+		for s := range p.Execute(in, done) {
+			result = append(result, s)
+		}
+
+		require.Len(t, result, 0)
+	})
+
+	t.Run("pipelines are reusable", func(t *testing.T) {
+		p := Then(NewPipeline[int](), typedStage(func(v int) int { return -v }))
+
+		for i := 0; i < 2; i++ {
+			result := make([]int, 0, 3)
+			for v := range p.Execute(source(1, 2, 3), nil) {
+				result = append(result, v)
+			}
+			require.Equal(t, []int{-1, -2, -3}, result)
+		}
+	})
+}